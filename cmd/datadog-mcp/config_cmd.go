@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pedrospdc/datadog-mcp/internal/tools"
+)
+
+const configUsage = "usage: datadog-mcp config validate [--config path]"
+
+// runConfig implements the "config" subcommand and returns the exit code.
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "validate" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to the YAML config file (default $XDG_CONFIG_HOME/datadog-mcp/config.yaml)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+
+	for _, org := range cfg.Orgs {
+		if _, err := org.Credentials(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+			return 1
+		}
+	}
+
+	source := "environment only"
+	if cfg.Path != "" {
+		source = cfg.Path + " + environment"
+	}
+	fmt.Printf("Configuration OK (%s)\n", source)
+	for _, org := range cfg.Orgs {
+		marker := ""
+		if org.Name == cfg.DefaultOrg {
+			marker = " (default)"
+		}
+		if org.Reloadable() {
+			marker += fmt.Sprintf(", keys reloaded every %s", cfg.CredentialRefresh)
+		}
+		fmt.Printf("  org %s: %s%s\n", org.Name, org.Site, marker)
+	}
+	policy := tools.Policy{
+		Enabled:  cfg.EnabledTools,
+		Disabled: cfg.DisabledTools,
+		ReadOnly: cfg.ReadOnly,
+	}
+	fmt.Printf("  read-only: %t\n", cfg.ReadOnly)
+	if cfg.MetricsListen != "" {
+		fmt.Printf("  metrics: http://%s/metrics\n", cfg.MetricsListen)
+	}
+	for _, reg := range tools.Preview(policy) {
+		if reg.Exposed {
+			fmt.Printf("  tool %s: exposed\n", reg.Name)
+		} else {
+			fmt.Printf("  tool %s: hidden (%s)\n", reg.Name, reg.Reason)
+		}
+	}
+	return 0
+}
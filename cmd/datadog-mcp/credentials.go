@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// orgClient pairs an org with its client and the keys the client is using.
+type orgClient struct {
+	org    config.Org
+	client *datadog.Client
+	creds  config.Credentials
+}
+
+// refreshCredentials reloads, every interval until ctx is cancelled, the keys
+// of the orgs that read them from files or a credential helper, so rotated
+// keys are picked up without a restart. A failed reload keeps the previous
+// keys in use.
+func refreshCredentials(ctx context.Context, clients []*orgClient, interval time.Duration) {
+	var reloadable []*orgClient
+	for _, c := range clients {
+		if c.org.Reloadable() {
+			reloadable = append(reloadable, c)
+		}
+	}
+	if len(reloadable) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, c := range reloadable {
+			creds, err := c.org.Credentials(ctx)
+			if err != nil {
+				log.Printf("Failed to reload credentials, keeping the current keys: %v", err)
+				continue
+			}
+			if creds != c.creds {
+				c.client.SetCredentials(creds)
+				c.creds = creds
+				log.Printf("Reloaded credentials for org %s", c.org.Name)
+			}
+		}
+	}
+}
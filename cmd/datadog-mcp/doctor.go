@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/tools"
+)
+
+// doctorTimeout bounds all checks of one org.
+const doctorTimeout = 30 * time.Second
+
+// runDoctor implements the "doctor" subcommand, writing its report to stdout
+// and errors to stderr, and returns the exit code.
+func runDoctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configPath := fs.String("config", "", "Path to the YAML config file (default $XDG_CONFIG_HOME/datadog-mcp/config.yaml)")
+	baseURL := fs.String("base-url", "", "Send the checks to this URL instead of each org's Datadog site, e.g. a proxy or a stand-in server")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+
+	var opts []datadog.Option
+	if *baseURL != "" {
+		opts = append(opts, datadog.WithBaseURL(*baseURL))
+	}
+	clients := make(map[string]*datadog.Client, len(cfg.Orgs))
+	for _, org := range cfg.Orgs {
+		creds, err := org.Credentials(context.Background())
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to load credentials: %v\n", err)
+			return 1
+		}
+		client := datadog.NewClient(org, opts...)
+		client.SetCredentials(creds)
+		clients[org.Name] = client
+	}
+
+	if !diagnose(context.Background(), stdout, cfg, clients) {
+		return 1
+	}
+	return 0
+}
+
+// diagnose checks the credentials of every org against the Datadog API,
+// writes a report to w naming the tools that will fail, and reports whether
+// every check passed.
+func diagnose(ctx context.Context, w io.Writer, cfg *config.Config, clients map[string]*datadog.Client) bool {
+	problems := 0
+	for _, org := range cfg.Orgs {
+		marker := ""
+		if org.Name == cfg.DefaultOrg {
+			marker = ", default"
+		}
+		fmt.Fprintf(w, "org %s (%s%s)\n", org.Name, org.Site, marker)
+
+		checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+		checks := clients[org.Name].Diagnose(checkCtx)
+		cancel()
+
+		var failing []string
+		for _, check := range checks {
+			if check.Err == nil {
+				fmt.Fprintf(w, "  ok    %s\n", check.Name)
+				continue
+			}
+			problems++
+			fmt.Fprintf(w, "  FAIL  %s: %v\n", check.Name, check.Err)
+
+			switch {
+			case check.Name == datadog.CheckAPIKey && check.Denied():
+				fmt.Fprintf(w, "        the API key is invalid, or does not belong to an org on %s\n", org.Site)
+				failing = tools.Names()
+			case check.Name == datadog.CheckAPIKey:
+			case check.Denied():
+				fmt.Fprintf(w, "        the application key lacks the %s scope, or its owner the matching permission\n", check.Name)
+				failing = append(failing, tools.UsingScope(check.Name)...)
+			default:
+				failing = append(failing, tools.UsingScope(check.Name)...)
+			}
+		}
+		if len(failing) > 0 {
+			fmt.Fprintf(w, "  tools that will fail: %s\n", strings.Join(failing, ", "))
+		}
+	}
+
+	if problems > 0 {
+		fmt.Fprintf(w, "%d check(s) failed\n", problems)
+		return false
+	}
+	fmt.Fprintln(w, "All checks passed")
+	return true
+}
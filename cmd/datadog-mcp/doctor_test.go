@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// standIn answers the doctor's checks like Datadog, refusing the paths in
+// denied with a 403.
+func standIn(t *testing.T, denied ...string) *httptest.Server {
+	t.Helper()
+	responses := map[string]string{
+		"/api/v1/validate":             `{"valid": true}`,
+		"/api/v1/query":                `{"status": "ok", "series": []}`,
+		"/api/v1/metrics":              `{"metrics": []}`,
+		"/api/v2/spans/events":         `{"data": []}`,
+		"/api/v2/services/definitions": `{"data": []}`,
+		"/api/v1/dashboard":            `{"dashboards": []}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		for _, path := range denied {
+			if r.URL.Path == path {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors": ["Forbidden"]}`))
+				return
+			}
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// writeConfig writes a config file with one org and returns its path.
+func writeConfig(t *testing.T) string {
+	t.Helper()
+	for _, name := range []string{"DD_API_KEY", "DD_APP_KEY", "DD_SITE", "DD_ORGS", "DD_DEFAULT_ORG", "DD_MCP_CONFIG"} {
+		t.Setenv(name, "")
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "orgs:\n  us1:\n    api_key: test-api-key\n    app_key: test-app-key\n"
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDoctorPasses(t *testing.T) {
+	server := standIn(t)
+	var stdout, stderr bytes.Buffer
+
+	code := runDoctor([]string{"--config", writeConfig(t), "--base-url", server.URL}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("exit code = %d, want 0\nstdout:\n%s\nstderr:\n%s", code, &stdout, &stderr)
+	}
+	for _, want := range []string{"org us1", "ok    api_key", "ok    timeseries_query", "ok    metrics_read", "ok    apm_read", "ok    apm_service_catalog_read", "ok    dashboards_read", "All checks passed"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, &stdout)
+		}
+	}
+}
+
+func TestDoctorMissingScope(t *testing.T) {
+	server := standIn(t, "/api/v1/metrics")
+	var stdout, stderr bytes.Buffer
+
+	code := runDoctor([]string{"--config", writeConfig(t), "--base-url", server.URL}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("exit code = %d, want 1\nstdout:\n%s\nstderr:\n%s", code, &stdout, &stderr)
+	}
+	for _, want := range []string{
+		"ok    timeseries_query",
+		"FAIL  metrics_read",
+		"lacks the metrics_read scope",
+		"tools that will fail: list_metrics, get_metric_metadata, list_metric_tags\n",
+		"1 check(s) failed",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, &stdout)
+		}
+	}
+}
+
+func TestDoctorInvalidAPIKey(t *testing.T) {
+	server := standIn(t, "/api/v1/validate")
+	var stdout, stderr bytes.Buffer
+
+	if code := runDoctor([]string{"--config", writeConfig(t), "--base-url", server.URL}, &stdout, &stderr); code != 1 {
+		t.Fatalf("exit code = %d, want 1", code)
+	}
+	if strings.Contains(stdout.String(), "timeseries_query") {
+		t.Errorf("scopes were checked after the API key was refused:\n%s", &stdout)
+	}
+	if !strings.Contains(stdout.String(), "tools that will fail: query_metrics,") {
+		t.Errorf("report does not list every tool as failing:\n%s", &stdout)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// shutdownTimeout bounds how long in-flight requests may drain on shutdown.
+	shutdownTimeout = 30 * time.Second
+	// sessionIdleTimeout closes HTTP sessions that have gone quiet, so a shared
+	// instance does not accumulate sessions from clients that went away.
+	sessionIdleTimeout = 30 * time.Minute
+)
+
+// newHTTPHandler returns an HTTP handler serving server over the streamable
+// HTTP transport, plus a /healthz endpoint for container health checks.
+func newHTTPHandler(server *mcp.Server) http.Handler {
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return server
+	}, &mcp.StreamableHTTPOptions{
+		SessionTimeout: sessionIdleTimeout,
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/", mcpHandler)
+	return mux
+}
+
+// serveHTTP serves server on ln until ctx is cancelled, then stops accepting
+// connections and waits for in-flight requests to drain.
+func serveHTTP(ctx context.Context, server *mcp.Server, ln net.Listener) error {
+	httpServer := &http.Server{
+		Handler:           newHTTPHandler(server),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- httpServer.Serve(ln)
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Draining HTTP connections (up to %s)", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		// Long-lived event streams never go idle on their own; close the
+		// remaining sessions and connections once the drain period is over.
+		log.Printf("Drain period expired, closing remaining sessions: %v", err)
+		for session := range server.Sessions() {
+			session.Close()
+		}
+		httpServer.Close()
+	}
+
+	if err := <-errChan; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTestServer returns a server with an echo tool.
+func newTestServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: serverVersion}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: input.Text}}}, nil, nil
+	})
+	return server
+}
+
+func connect(t *testing.T, ctx context.Context, endpoint string) *mcp.ClientSession {
+	t.Helper()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "v0.0.1"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: endpoint}, nil)
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", endpoint, err)
+	}
+	return session
+}
+
+func TestHTTPHandlerCallTool(t *testing.T) {
+	ts := httptest.NewServer(newHTTPHandler(newTestServer()))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/healthz status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	ctx := context.Background()
+	session := connect(t, ctx, ts.URL)
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": "hello"}})
+	if err != nil {
+		t.Fatalf("CallTool: %v", err)
+	}
+	if res.IsError {
+		t.Fatalf("echo failed: %v", res.Content)
+	}
+	if text := res.Content[0].(*mcp.TextContent).Text; text != "hello" {
+		t.Errorf("echo text = %q, want hello", text)
+	}
+}
+
+func TestServeHTTPDrains(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: serverVersion}, nil)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mcp.AddTool(server, &mcp.Tool{Name: "wait"}, func(ctx context.Context, req *mcp.CallToolRequest, input struct{}) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-release
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	served := make(chan error, 1)
+	go func() {
+		served <- serveHTTP(ctx, server, ln)
+	}()
+
+	session := connect(t, context.Background(), "http://"+ln.Addr().String())
+	called := make(chan error, 1)
+	go func() {
+		res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "wait"})
+		if err == nil && (res.IsError || res.Content[0].(*mcp.TextContent).Text != "done") {
+			err = errors.New("unexpected result")
+		}
+		called <- err
+	}()
+
+	<-started
+	cancel()
+
+	// The in-flight call holds the server open, while new connections are
+	// refused.
+	select {
+	case err := <-served:
+		t.Fatalf("serveHTTP returned with a call in flight: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	if conn, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		conn.Close()
+		t.Error("server accepted a connection while draining")
+	}
+
+	close(release)
+	if err := <-called; err != nil {
+		t.Fatalf("in-flight call: %v", err)
+	}
+	session.Close()
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("serveHTTP: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serveHTTP did not return after the in-flight call drained")
+	}
+}
@@ -2,9 +2,16 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -20,14 +27,65 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "doctor":
+			os.Exit(runDoctor(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	configPath := flag.String("config", "", "Path to the YAML config file (default $XDG_CONFIG_HOME/datadog-mcp/config.yaml)")
+	transport := flag.String("transport", "stdio", "Transport to serve MCP over: stdio or http")
+	listen := flag.String("listen", ":8080", "Address to listen on when --transport=http")
+	record := flag.String("record", "", "Record every Datadog request and response, with keys scrubbed, into this directory")
+	replay := flag.String("replay", "", "Answer Datadog requests from the exchanges recorded in this directory instead of the network")
+	check := flag.Bool("check", false, "Check credentials and permissions against Datadog before serving, and exit if any check fails")
+	flag.Parse()
+
+	if *transport != "stdio" && *transport != "http" {
+		log.Fatalf("Unknown transport %q: must be stdio or http", *transport)
+	}
+	if *record != "" && *replay != "" {
+		log.Fatalf("--record and --replay cannot be combined")
+	}
+
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := loadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Create Datadog client
-	ddClient := datadog.NewClient(cfg)
+	// Create a Datadog client per configured org
+	backends := make(map[string]datadog.Backend, len(cfg.Orgs))
+	clients := make([]*orgClient, 0, len(cfg.Orgs))
+	byName := make(map[string]*datadog.Client, len(cfg.Orgs))
+	for _, org := range cfg.Orgs {
+		creds, err := org.Credentials(context.Background())
+		if err != nil {
+			log.Fatalf("Failed to load credentials: %v", err)
+		}
+		httpTransport, err := orgTransport(org.Name, *record, *replay)
+		if err != nil {
+			log.Fatalf("Failed to set up org %s: %v", org.Name, err)
+		}
+		client := datadog.NewClient(org,
+			datadog.WithConcurrency(cfg.Concurrency),
+			datadog.WithTransport(httpTransport),
+		)
+		client.SetCredentials(creds)
+		backends[org.Name] = client
+		clients = append(clients, &orgClient{org: org, client: client, creds: creds})
+		byName[org.Name] = client
+	}
+	orgs := datadog.NewOrgs(cfg.DefaultOrg, backends)
+
+	if *check || cfg.StartupCheck {
+		if !diagnose(context.Background(), os.Stderr, cfg, byName) {
+			log.Fatalf("Startup check failed; run %s doctor for details", serverName)
+		}
+	}
 
 	// Create MCP server
 	server := mcp.NewServer(&mcp.Implementation{
@@ -35,8 +93,26 @@ func main() {
 		Version: serverVersion,
 	}, nil)
 
-	// Register all tools
-	tools.RegisterAll(server, ddClient)
+	auditLogger, closeAudit, err := openAuditLog(cfg.AuditLog)
+	if err != nil {
+		log.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer closeAudit()
+
+	// Register the tools allowed by the configured policy
+	registrations := tools.RegisterAll(server, orgs, tools.Options{
+		DefaultTimeout: cfg.ToolTimeout,
+		Timeouts:       cfg.ToolTimeouts,
+		Lookbacks:      cfg.Lookbacks,
+		Limits:         cfg.Limits,
+		Policy: tools.Policy{
+			Enabled:  cfg.EnabledTools,
+			Disabled: cfg.DisabledTools,
+			ReadOnly: cfg.ReadOnly,
+		},
+		Audit: auditLogger,
+	})
+	logRegistrations(registrations, cfg.ReadOnly)
 
 	// Set up graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -51,9 +127,103 @@ func main() {
 		cancel()
 	}()
 
+	go refreshCredentials(ctx, clients, cfg.CredentialRefresh)
+
+	if cfg.MetricsListen != "" {
+		if err := startMetricsServer(ctx, cfg.MetricsListen); err != nil {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}
+
+	if *transport == "http" {
+		ln, err := net.Listen("tcp", *listen)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", *listen, err)
+		}
+		log.Printf("Starting %s %s on %s (streamable HTTP)", serverName, serverVersion, ln.Addr())
+		if err := serveHTTP(ctx, server, ln); err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		return
+	}
+
 	// Run server with stdio transport
 	log.Printf("Starting %s %s", serverName, serverVersion)
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+// loadConfig loads the configuration and checks that every tool it refers
+// to exists.
+func loadConfig(path string) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := tools.CheckNames(cfg.ToolNames()); err != nil {
+		return nil, err
+	}
+	if err := tools.CheckPatterns(cfg.EnabledTools); err != nil {
+		return nil, fmt.Errorf("enabled tools: %w", err)
+	}
+	if err := tools.CheckPatterns(cfg.DisabledTools); err != nil {
+		return nil, fmt.Errorf("disabled tools: %w", err)
+	}
+	return cfg, nil
+}
+
+// orgTransport returns the HTTP transport of the named org's client: one
+// recording exchanges into, or replaying them from, a subdirectory per org,
+// or the default transport.
+func orgTransport(org, recordDir, replayDir string) (http.RoundTripper, error) {
+	switch {
+	case recordDir != "":
+		dir := filepath.Join(recordDir, org)
+		log.Printf("Recording Datadog traffic of org %s into %s", org, dir)
+		return datadog.NewRecorder(dir, http.DefaultTransport)
+	case replayDir != "":
+		dir := filepath.Join(replayDir, org)
+		log.Printf("Replaying Datadog traffic of org %s from %s", org, dir)
+		return datadog.NewReplayer(dir)
+	default:
+		return http.DefaultTransport, nil
+	}
+}
+
+// openAuditLog returns a JSON logger writing to path, which may be "stderr",
+// and a function closing it. An empty path disables auditing.
+func openAuditLog(path string) (*slog.Logger, func(), error) {
+	switch path {
+	case "":
+		return nil, func() {}, nil
+	case "stderr":
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil)), func() {}, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Writing audit log to %s", path)
+	return slog.New(slog.NewJSONHandler(f, nil)), func() { f.Close() }, nil
+}
+
+// logRegistrations logs which tools are exposed to clients and why the
+// others are not.
+func logRegistrations(registrations []tools.Registration, readOnly bool) {
+	var exposed []string
+	for _, reg := range registrations {
+		if reg.Exposed {
+			exposed = append(exposed, reg.Name)
+		} else {
+			log.Printf("Not exposing tool %s: %s", reg.Name, reg.Reason)
+		}
+	}
+
+	mode := "read-write"
+	if readOnly {
+		mode = "read-only"
+	}
+	log.Printf("Exposing %d tools (%s): %s", len(exposed), mode, strings.Join(exposed, ", "))
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+// startMetricsServer serves Prometheus metrics on addr under /metrics until
+// ctx is cancelled. It fails immediately if addr cannot be bound.
+func startMetricsServer(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", telemetry.Handler())
+	httpServer := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := httpServer.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		httpServer.Close()
+	}()
+
+	log.Printf("Serving metrics on http://%s/metrics", ln.Addr())
+	return nil
+}
@@ -1,37 +1,557 @@
 package config
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
-// Config holds the Datadog API configuration.
-type Config struct {
+// DefaultToolTimeout bounds a single tool call when no timeout is configured.
+const DefaultToolTimeout = 60 * time.Second
+
+// DefaultOrgName names the organization configured through DD_API_KEY,
+// DD_APP_KEY and DD_SITE.
+const DefaultOrgName = "default"
+
+// DefaultSite is the Datadog site used when none is configured.
+const DefaultSite = "datadoghq.com"
+
+// Sites lists the Datadog sites an organization may live on.
+var Sites = []string{
+	"datadoghq.com",
+	"us3.datadoghq.com",
+	"us5.datadoghq.com",
+	"datadoghq.eu",
+	"ap1.datadoghq.com",
+	"ap2.datadoghq.com",
+	"ddog-gov.com",
+}
+
+// DefaultLookbacks holds how far back time-bound tools look when the caller
+// does not pass a start time, keyed by tool name.
+var DefaultLookbacks = map[string]time.Duration{
+	"query_metrics":    time.Hour,
+	"query_apm_stats":  time.Hour,
+	"query_spans":      15 * time.Minute,
+	"query_timeseries": time.Hour,
+	"list_metrics":     24 * time.Hour,
+}
+
+// DefaultLimits holds the output limits applied when none are configured.
+var DefaultLimits = Limits{
+	MaxSeries:      100,
+	MaxDataPoints:  300,
+	MaxSpans:       20,
+	MaxDashboards:  50,
+	MaxMetrics:     100,
+	MaxOutputChars: 8000,
+}
+
+// ConcurrencyFamilies lists the endpoint families whose concurrent Datadog
+// requests can be limited separately.
+var ConcurrencyFamilies = []string{"metrics", "spans", "dashboards", "catalog"}
+
+// DefaultConcurrency holds the concurrency limits applied when none are
+// configured.
+var DefaultConcurrency = Concurrency{
+	Global: 16,
+	Families: map[string]int{
+		"metrics":    8,
+		"spans":      4,
+		"dashboards": 4,
+		"catalog":    4,
+	},
+}
+
+// Org holds the credentials and site of one Datadog organization.
+type Org struct {
+	Name   string
 	APIKey string
 	AppKey string
 	Site   string
+
+	// APIKeyFile and AppKeyFile name files holding the keys, used when the
+	// corresponding key is not set directly.
+	APIKeyFile string
+	AppKeyFile string
+	// CredentialHelper is a command printing the keys as JSON, used for any
+	// key that is neither set directly nor read from a file.
+	CredentialHelper []string
+}
+
+// Limits bounds how much data tools return.
+type Limits struct {
+	// MaxSeries is the default number of series query_metrics returns.
+	MaxSeries int `yaml:"max_series"`
+	// MaxDataPoints is the default number of points per series query_metrics returns.
+	MaxDataPoints int `yaml:"max_data_points"`
+	// MaxSpans is the number of spans query_spans lists in its text summary.
+	MaxSpans int `yaml:"max_spans"`
+	// MaxDashboards is the number of dashboards list_dashboards lists in its text summary.
+	MaxDashboards int `yaml:"max_dashboards"`
+	// MaxMetrics is the default page size of list_metrics.
+	MaxMetrics int `yaml:"max_metrics"`
+	// MaxOutputChars is the default character budget of a tool's text summary.
+	MaxOutputChars int `yaml:"max_output_chars"`
+}
+
+// Concurrency bounds the number of simultaneous requests sent to each
+// Datadog org.
+type Concurrency struct {
+	// Global bounds requests across all endpoint families. Zero means unlimited.
+	Global int `yaml:"global"`
+	// Families bounds requests per endpoint family, keyed by family name.
+	// Zero or a missing family means unlimited.
+	Families map[string]int `yaml:"families"`
+}
+
+// Config holds the Datadog API configuration.
+type Config struct {
+	// Path is the configuration file that was loaded, if any.
+	Path string
+
+	// Orgs lists the configured Datadog organizations.
+	Orgs []Org
+	// DefaultOrg names the organization used when a tool call does not pick one.
+	DefaultOrg string
+	// StartupCheck verifies credentials and permissions against Datadog
+	// before the server starts.
+	StartupCheck bool
+	// CredentialRefresh is how often keys read from files or credential
+	// helpers are reloaded.
+	CredentialRefresh time.Duration
+
+	// ToolTimeout bounds every tool call unless overridden in ToolTimeouts.
+	ToolTimeout time.Duration
+	// ToolTimeouts holds per-tool timeouts keyed by tool name.
+	ToolTimeouts map[string]time.Duration
+	// Lookbacks holds per-tool default time ranges keyed by tool name.
+	Lookbacks map[string]time.Duration
+	// Limits bounds tool output.
+	Limits Limits
+	// Concurrency bounds simultaneous requests to each org.
+	Concurrency Concurrency
+	// EnabledTools lists name patterns of the tools to expose. Empty means all tools.
+	EnabledTools []string
+	// DisabledTools lists name patterns of tools to hide, even if enabled.
+	DisabledTools []string
+	// ReadOnly refuses to expose any tool that can modify Datadog.
+	ReadOnly bool
+
+	// AuditLog is the file receiving a JSON audit entry per tool call, or
+	// "stderr". Empty disables auditing.
+	AuditLog string
+	// MetricsListen is the address serving Prometheus metrics on /metrics.
+	// Empty disables the metrics listener.
+	MetricsListen string
+}
+
+// Org returns the organization with the given name.
+func (c *Config) Org(name string) (Org, bool) {
+	for _, org := range c.Orgs {
+		if org.Name == name {
+			return org, true
+		}
+	}
+	return Org{}, false
+}
+
+// ToolNames returns every exact tool name the configuration refers to, so
+// callers can check them against the tools that actually exist.
+func (c *Config) ToolNames() []string {
+	names := make([]string, 0, len(c.ToolTimeouts)+len(c.Lookbacks))
+	for name := range c.ToolTimeouts {
+		names = append(names, name)
+	}
+	for name := range c.Lookbacks {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Load reads the configuration file at path, then applies environment
+// variable overrides and validates the result.
+//
+// If path is empty, DD_MCP_CONFIG is used, falling back to DefaultPath when
+// that file exists. Without a file, configuration comes from the environment
+// alone: DD_API_KEY, DD_APP_KEY and DD_SITE configure an organization named
+// "default"; DD_API_KEY_FILE, DD_APP_KEY_FILE and DD_CREDENTIAL_HELPER may
+// supply its keys instead. Additional organizations are listed in DD_ORGS,
+// e.g. DD_ORGS=us1,eu1, and read from DD_<NAME>_API_KEY, DD_<NAME>_APP_KEY,
+// DD_<NAME>_SITE and the DD_<NAME>_ variants of the key sources above.
+// DD_DEFAULT_ORG selects the organization tools use when the caller does not
+// name one.
+//
+// With a file, DD_<NAME>_* override the keys and site of the file's
+// organizations. Setting DD_API_KEY, DD_APP_KEY or another of the key
+// sources above also adds an organization named "default" alongside them,
+// unless the file defines one. It does not become the default organization:
+// that stays default_org, or the file's first organization by name.
+func Load(path string) (*Config, error) {
+	cfg := &Config{
+		ToolTimeout:       DefaultToolTimeout,
+		CredentialRefresh: DefaultCredentialRefresh,
+		ToolTimeouts:      make(map[string]time.Duration),
+		Lookbacks:         make(map[string]time.Duration),
+		Limits:            DefaultLimits,
+		Concurrency: Concurrency{
+			Global:   DefaultConcurrency.Global,
+			Families: maps.Clone(DefaultConcurrency.Families),
+		},
+	}
+	for name, d := range DefaultLookbacks {
+		cfg.Lookbacks[name] = d
+	}
+
+	if path == "" {
+		path = os.Getenv("DD_MCP_CONFIG")
+	}
+	if path == "" {
+		if p := DefaultPath(); p != "" {
+			if _, err := os.Stat(p); err == nil {
+				path = p
+			}
+		}
+	}
+	if path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+		cfg.Path = path
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
 }
 
-// Load reads configuration from environment variables.
-func Load() (*Config, error) {
-	apiKey := os.Getenv("DD_API_KEY")
-	if apiKey == "" {
-		return nil, errors.New("DD_API_KEY environment variable is required")
+// applyEnv overrides cfg with the values set in environment variables.
+func applyEnv(cfg *Config) error {
+	defaultOrgSet := false
+	for _, name := range []string{"API_KEY", "APP_KEY", "API_KEY_FILE", "APP_KEY_FILE", "CREDENTIAL_HELPER"} {
+		defaultOrgSet = defaultOrgSet || os.Getenv("DD_"+name) != ""
+	}
+	if defaultOrgSet || (len(cfg.Orgs) == 0 && os.Getenv("DD_ORGS") == "") {
+		cfg.addOrg(DefaultOrgName)
+	}
+
+	if v := os.Getenv("DD_ORGS"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.addOrg(name)
+			}
+		}
+	}
+
+	for i := range cfg.Orgs {
+		org := &cfg.Orgs[i]
+		prefix := envPrefix(org.Name)
+		if v := os.Getenv(prefix + "API_KEY"); v != "" {
+			org.APIKey = v
+		}
+		if v := os.Getenv(prefix + "APP_KEY"); v != "" {
+			org.AppKey = v
+		}
+		// A key file set in the environment replaces a key from the config file.
+		if v := os.Getenv(prefix + "API_KEY_FILE"); v != "" {
+			org.APIKeyFile = v
+			if os.Getenv(prefix+"API_KEY") == "" {
+				org.APIKey = ""
+			}
+		}
+		if v := os.Getenv(prefix + "APP_KEY_FILE"); v != "" {
+			org.AppKeyFile = v
+			if os.Getenv(prefix+"APP_KEY") == "" {
+				org.AppKey = ""
+			}
+		}
+		if v := os.Getenv(prefix + "CREDENTIAL_HELPER"); v != "" {
+			command, err := parseCommand(v)
+			if err != nil {
+				return fmt.Errorf("invalid %sCREDENTIAL_HELPER: %w", prefix, err)
+			}
+			org.CredentialHelper = command
+		}
+		if v := os.Getenv(prefix + "SITE"); v != "" {
+			org.Site = v
+		}
+		if org.Site == "" {
+			org.Site = DefaultSite
+		}
+	}
+
+	if v := os.Getenv("DD_DEFAULT_ORG"); v != "" {
+		cfg.DefaultOrg = v
+	}
+	if cfg.DefaultOrg == "" && len(cfg.Orgs) > 0 {
+		cfg.DefaultOrg = cfg.Orgs[0].Name
+	}
+
+	if v := os.Getenv("DD_MCP_CREDENTIAL_REFRESH"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid DD_MCP_CREDENTIAL_REFRESH %q: must be a positive duration, e.g. 5m", v)
+		}
+		cfg.CredentialRefresh = d
+	}
+
+	if v := os.Getenv("DD_MCP_TOOL_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid DD_MCP_TOOL_TIMEOUT %q: must be a positive duration, e.g. 30s", v)
+		}
+		cfg.ToolTimeout = d
+	}
+
+	toolTimeouts, err := parseToolTimeouts(os.Getenv("DD_MCP_TOOL_TIMEOUTS"))
+	if err != nil {
+		return fmt.Errorf("invalid DD_MCP_TOOL_TIMEOUTS: %w", err)
 	}
+	for name, d := range toolTimeouts {
+		cfg.ToolTimeouts[name] = d
+	}
+
+	if v := os.Getenv("DD_MCP_MAX_OUTPUT_CHARS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid DD_MCP_MAX_OUTPUT_CHARS %q: must be a positive integer", v)
+		}
+		cfg.Limits.MaxOutputChars = n
+	}
+
+	if v := os.Getenv("DD_MCP_MAX_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid DD_MCP_MAX_CONCURRENCY %q: must be a non-negative integer", v)
+		}
+		cfg.Concurrency.Global = n
+	}
+	familyLimits, err := parseFamilyConcurrency(os.Getenv("DD_MCP_FAMILY_CONCURRENCY"))
+	if err != nil {
+		return fmt.Errorf("invalid DD_MCP_FAMILY_CONCURRENCY: %w", err)
+	}
+	for family, n := range familyLimits {
+		cfg.Concurrency.Families[family] = n
+	}
+
+	if v := os.Getenv("DD_MCP_ENABLED_TOOLS"); v != "" {
+		cfg.EnabledTools = splitList(v)
+	}
+	if v := os.Getenv("DD_MCP_DISABLED_TOOLS"); v != "" {
+		cfg.DisabledTools = splitList(v)
+	}
+	if v := os.Getenv("DD_MCP_AUDIT_LOG"); v != "" {
+		cfg.AuditLog = v
+	}
+	if v := os.Getenv("DD_MCP_METRICS_LISTEN"); v != "" {
+		cfg.MetricsListen = v
+	}
+
+	if v := os.Getenv("DD_MCP_STARTUP_CHECK"); v != "" {
+		check, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DD_MCP_STARTUP_CHECK %q: must be true or false", v)
+		}
+		cfg.StartupCheck = check
+	}
+
+	if v := os.Getenv("DD_MCP_READ_ONLY"); v != "" {
+		readOnly, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid DD_MCP_READ_ONLY %q: must be true or false", v)
+		}
+		cfg.ReadOnly = readOnly
+	}
+
+	return nil
+}
+
+// addOrg appends an empty organization named name unless it already exists.
+func (c *Config) addOrg(name string) {
+	if _, exists := c.Org(name); !exists {
+		c.Orgs = append(c.Orgs, Org{Name: name})
+	}
+}
+
+// Validate reports the first problem found in the configuration.
+func (c *Config) Validate() error {
+	if len(c.Orgs) == 0 {
+		return errors.New("no Datadog org configured: set DD_API_KEY and DD_APP_KEY, or add orgs to the config file")
+	}
+
+	for _, org := range c.Orgs {
+		prefix := envPrefix(org.Name)
+		api, app := org.HasKeySource()
+		if !api {
+			return fmt.Errorf("org %q: API key is required (set %sAPI_KEY, %sAPI_KEY_FILE or orgs.%s.api_key)", org.Name, prefix, prefix, org.Name)
+		}
+		if !app {
+			return fmt.Errorf("org %q: application key is required (set %sAPP_KEY, %sAPP_KEY_FILE or orgs.%s.app_key)", org.Name, prefix, prefix, org.Name)
+		}
+		if !slices.Contains(Sites, org.Site) {
+			return fmt.Errorf("org %q: unknown site %q, must be one of %s", org.Name, org.Site, strings.Join(Sites, ", "))
+		}
+	}
+
+	if _, ok := c.Org(c.DefaultOrg); !ok {
+		return fmt.Errorf("default org %q is not a configured org", c.DefaultOrg)
+	}
+
+	if c.CredentialRefresh <= 0 {
+		return errors.New("credentials.refresh must be a positive duration")
+	}
+	if c.ToolTimeout <= 0 {
+		return errors.New("timeouts.default must be a positive duration")
+	}
+	for name, d := range c.ToolTimeouts {
+		if d <= 0 {
+			return fmt.Errorf("timeout for %s must be a positive duration", name)
+		}
+	}
+	for name, d := range c.Lookbacks {
+		if d <= 0 {
+			return fmt.Errorf("time range for %s must be a positive duration", name)
+		}
+	}
+
+	limits := map[string]int{
+		"max_series":       c.Limits.MaxSeries,
+		"max_data_points":  c.Limits.MaxDataPoints,
+		"max_spans":        c.Limits.MaxSpans,
+		"max_dashboards":   c.Limits.MaxDashboards,
+		"max_metrics":      c.Limits.MaxMetrics,
+		"max_output_chars": c.Limits.MaxOutputChars,
+	}
+	for name, v := range limits {
+		if v <= 0 {
+			return fmt.Errorf("limits.%s must be positive, got %d", name, v)
+		}
+	}
+
+	if c.Concurrency.Global < 0 {
+		return fmt.Errorf("concurrency.global must not be negative, got %d", c.Concurrency.Global)
+	}
+	for family, n := range c.Concurrency.Families {
+		if !slices.Contains(ConcurrencyFamilies, family) {
+			return fmt.Errorf("unknown concurrency family %q, must be one of %s", family, strings.Join(ConcurrencyFamilies, ", "))
+		}
+		if n < 0 {
+			return fmt.Errorf("concurrency for %s must not be negative, got %d", family, n)
+		}
+	}
+
+	return nil
+}
+
+// envPrefix returns the prefix of the environment variables configuring the
+// named organization.
+func envPrefix(name string) string {
+	if name == DefaultOrgName {
+		return "DD_"
+	}
+	return "DD_" + envName(name) + "_"
+}
+
+// envName converts an org name to the form used in environment variable
+// names, e.g. "eu-1" becomes "EU_1".
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// splitList splits a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseCommand parses a command line. A JSON array of strings gives the
+// program and its arguments as they are, e.g. ["/opt/my tools/keys",
+// "--profile", "prod"]; anything else is split at whitespace, without any
+// quoting.
+func parseCommand(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") {
+		return strings.Fields(s), nil
+	}
+	var command []string
+	if err := json.Unmarshal([]byte(s), &command); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of strings: %w", err)
+	}
+	if len(command) == 0 || command[0] == "" {
+		return nil, errors.New("expected a JSON array of strings starting with the program")
+	}
+	return command, nil
+}
+
+// parseToolTimeouts parses a comma-separated list of tool=duration pairs,
+// e.g. "query_spans=2m,list_metrics=90s".
+func parseToolTimeouts(s string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	if s == "" {
+		return timeouts, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected tool=duration, got %q", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("timeout for %s must be a positive duration, got %q", name, value)
+		}
+		timeouts[name] = d
+	}
+
+	return timeouts, nil
+}
 
-	appKey := os.Getenv("DD_APP_KEY")
-	if appKey == "" {
-		return nil, errors.New("DD_APP_KEY environment variable is required")
+// parseFamilyConcurrency parses a comma-separated list of family=limit pairs,
+// e.g. "metrics=8,spans=2".
+func parseFamilyConcurrency(s string) (map[string]int, error) {
+	limits := make(map[string]int)
+	if s == "" {
+		return limits, nil
 	}
 
-	site := os.Getenv("DD_SITE")
-	if site == "" {
-		site = "datadoghq.com"
+	for _, pair := range strings.Split(s, ",") {
+		family, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || family == "" {
+			return nil, fmt.Errorf("expected family=limit, got %q", pair)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("concurrency for %s must be a non-negative integer, got %q", family, value)
+		}
+		limits[family] = n
 	}
 
-	return &Config{
-		APIKey: apiKey,
-		AppKey: appKey,
-		Site:   site,
-	}, nil
+	return limits, nil
 }
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setEnv sets the given environment variables for the duration of the test,
+// and clears the other Datadog variables and the default config file.
+func setEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "DD_") {
+			t.Setenv(name, "")
+		}
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+// writeConfig writes a config file with the given contents and returns its
+// path.
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadOrgs(t *testing.T) {
+	setEnv(t, map[string]string{
+		"DD_API_KEY":         "api",
+		"DD_APP_KEY":         "app",
+		"DD_ORGS":            "eu-1, sandbox",
+		"DD_EU_1_API_KEY":    "eu-api",
+		"DD_EU_1_APP_KEY":    "eu-app",
+		"DD_EU_1_SITE":       "datadoghq.eu",
+		"DD_SANDBOX_API_KEY": "sandbox-api",
+		"DD_SANDBOX_APP_KEY": "sandbox-app",
+		"DD_DEFAULT_ORG":     "eu-1",
+	})
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, org := range cfg.Orgs {
+		names = append(names, org.Name)
+	}
+	if strings.Join(names, ",") != "default,eu-1,sandbox" {
+		t.Errorf("orgs = %v, want default, eu-1 and sandbox", names)
+	}
+	if cfg.DefaultOrg != "eu-1" {
+		t.Errorf("DefaultOrg = %q, want eu-1", cfg.DefaultOrg)
+	}
+	if eu, _ := cfg.Org("eu-1"); eu.APIKey != "eu-api" || eu.Site != "datadoghq.eu" {
+		t.Errorf("eu-1 = %+v, want its own key and site", eu)
+	}
+	if sandbox, _ := cfg.Org("sandbox"); sandbox.Site != "datadoghq.com" {
+		t.Errorf("sandbox site = %q, want the datadoghq.com default", sandbox.Site)
+	}
+}
+
+func TestLoadOrgsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"no keys", map[string]string{}, "set DD_API_KEY"},
+		{"missing org key", map[string]string{"DD_ORGS": "eu1", "DD_EU1_API_KEY": "api"}, "set DD_EU1_APP_KEY"},
+		{"unknown default", map[string]string{"DD_API_KEY": "api", "DD_APP_KEY": "app", "DD_DEFAULT_ORG": "eu1"}, `default org "eu1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnv(t, tt.env)
+			_, err := Load("")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() error = %v, want one mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+const testConfig = `
+default_org: us1
+orgs:
+  us1:
+    api_key: file-api
+    app_key: file-app
+  eu1:
+    api_key: eu-api
+    app_key: eu-app
+    site: datadoghq.eu
+timeouts:
+  default: 45s
+  tools:
+    query_spans: 2m
+time_ranges:
+  query_spans: 30m
+limits:
+  max_spans: 5
+tools:
+  enabled: ["query_*"]
+`
+
+func TestLoadFile(t *testing.T) {
+	setEnv(t, nil)
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Orgs) != 2 || cfg.DefaultOrg != "us1" {
+		t.Errorf("orgs = %+v, default %q; want eu1 and us1, default us1", cfg.Orgs, cfg.DefaultOrg)
+	}
+	if us1, _ := cfg.Org("us1"); us1.Site != DefaultSite {
+		t.Errorf("us1 site = %q, want %s", us1.Site, DefaultSite)
+	}
+	if cfg.ToolTimeout != 45*time.Second || cfg.ToolTimeouts["query_spans"] != 2*time.Minute {
+		t.Errorf("timeouts = %s, %v; want 45s and 2m for query_spans", cfg.ToolTimeout, cfg.ToolTimeouts)
+	}
+	if cfg.Lookbacks["query_spans"] != 30*time.Minute || cfg.Lookbacks["query_metrics"] != time.Hour {
+		t.Errorf("lookbacks = %v, want 30m for query_spans and the default 1h for query_metrics", cfg.Lookbacks)
+	}
+	if cfg.Limits.MaxSpans != 5 || cfg.Limits.MaxSeries != DefaultLimits.MaxSeries {
+		t.Errorf("limits = %+v, want max_spans 5 and the other defaults", cfg.Limits)
+	}
+	if len(cfg.EnabledTools) != 1 || cfg.EnabledTools[0] != "query_*" {
+		t.Errorf("enabled tools = %v, want [query_*]", cfg.EnabledTools)
+	}
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	setEnv(t, map[string]string{
+		"DD_US1_API_KEY":       "env-api",
+		"DD_EU1_SITE":          "us5.datadoghq.com",
+		"DD_DEFAULT_ORG":       "eu1",
+		"DD_MCP_TOOL_TIMEOUT":  "10s",
+		"DD_MCP_TOOL_TIMEOUTS": "query_spans=20s",
+	})
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if us1, _ := cfg.Org("us1"); us1.APIKey != "env-api" || us1.AppKey != "file-app" {
+		t.Errorf("us1 = %+v, want the API key from the environment and the app key from the file", us1)
+	}
+	if eu1, _ := cfg.Org("eu1"); eu1.Site != "us5.datadoghq.com" {
+		t.Errorf("eu1 site = %q, want us5.datadoghq.com", eu1.Site)
+	}
+	if cfg.DefaultOrg != "eu1" {
+		t.Errorf("DefaultOrg = %q, want eu1", cfg.DefaultOrg)
+	}
+	if cfg.ToolTimeout != 10*time.Second || cfg.ToolTimeouts["query_spans"] != 20*time.Second {
+		t.Errorf("timeouts = %s, %v; want 10s and 20s for query_spans", cfg.ToolTimeout, cfg.ToolTimeouts)
+	}
+}
+
+func TestLoadEnvKeysAddDefaultOrg(t *testing.T) {
+	// DD_API_KEY and DD_APP_KEY configure the "default" org alongside the
+	// orgs in the file, without changing which org is the default.
+	setEnv(t, map[string]string{"DD_API_KEY": "api", "DD_APP_KEY": "app"})
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if org, ok := cfg.Org(DefaultOrgName); !ok || org.APIKey != "api" {
+		t.Errorf("default org = %+v, want one with the DD_API_KEY key", org)
+	}
+	if len(cfg.Orgs) != 3 || cfg.DefaultOrg != "us1" {
+		t.Errorf("orgs = %+v, default %q; want eu1, us1 and default, default us1", cfg.Orgs, cfg.DefaultOrg)
+	}
+}
+
+func TestLoadFileUnknownKey(t *testing.T) {
+	setEnv(t, nil)
+	_, err := Load(writeConfig(t, "limits:\n  max_serie: 10\n"))
+	if err == nil || !strings.Contains(err.Error(), "max_serie") {
+		t.Errorf("Load() error = %v, want one naming the unknown key max_serie", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{
+			Orgs:              []Org{{Name: "default", APIKey: "api", AppKey: "app", Site: DefaultSite}},
+			DefaultOrg:        "default",
+			ToolTimeout:       time.Minute,
+			CredentialRefresh: DefaultCredentialRefresh,
+			Limits:            DefaultLimits,
+		}
+	}
+	if err := valid().Validate(); err != nil {
+		t.Fatalf("Validate() = %v for a valid config", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"unknown site", func(c *Config) { c.Orgs[0].Site = "datadoghq.co" }, `unknown site "datadoghq.co"`},
+		{"zero limit", func(c *Config) { c.Limits.MaxSpans = 0 }, "limits.max_spans must be positive"},
+		{"negative limit", func(c *Config) { c.Limits.MaxSeries = -1 }, "limits.max_series must be positive"},
+		{"negative timeout", func(c *Config) { c.ToolTimeouts = map[string]time.Duration{"query_spans": -time.Second} }, "timeout for query_spans"},
+		{"no orgs", func(c *Config) { c.Orgs = nil }, "no Datadog org configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.modify(c)
+			if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want an error mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseToolTimeouts(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{in: "", want: map[string]time.Duration{}},
+		{in: "query_spans=2m, list_metrics=90s", want: map[string]time.Duration{"query_spans": 2 * time.Minute, "list_metrics": 90 * time.Second}},
+		{in: "query_spans", wantErr: true},
+		{in: "=30s", wantErr: true},
+		{in: "query_spans=soon", wantErr: true},
+		{in: "query_spans=-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseToolTimeouts(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseToolTimeouts(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseToolTimeouts(%q): %v", tt.in, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("parseToolTimeouts(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+		for name, d := range tt.want {
+			if got[name] != d {
+				t.Errorf("parseToolTimeouts(%q)[%s] = %s, want %s", tt.in, name, got[name], d)
+			}
+		}
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{in: "/usr/local/bin/datadog-keys --profile prod", want: []string{"/usr/local/bin/datadog-keys", "--profile", "prod"}},
+		{in: ` ["/opt/my tools/keys", "--profile", "prod"]`, want: []string{"/opt/my tools/keys", "--profile", "prod"}},
+		{in: `["keys", "--name", "it's \"quoted\""]`, want: []string{"keys", "--name", `it's "quoted"`}},
+		{in: `["keys", 1]`, wantErr: true},
+		{in: `[]`, wantErr: true},
+		{in: `["", "--profile"]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCommand(tt.in)
+		if (err != nil) != tt.wantErr || !slices.Equal(got, tt.want) {
+			t.Errorf("parseCommand(%q) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
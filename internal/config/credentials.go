@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultCredentialRefresh is how often keys read from files or a credential
+// helper are reloaded when no interval is configured.
+const DefaultCredentialRefresh = 5 * time.Minute
+
+// credentialHelperTimeout bounds a single run of a credential helper.
+const credentialHelperTimeout = 30 * time.Second
+
+// Credentials holds the keys used to authenticate against one organization.
+type Credentials struct {
+	APIKey string `json:"api_key"`
+	AppKey string `json:"app_key"`
+}
+
+// HasKeySource reports whether the organization has a source for both keys,
+// without reading any of them.
+func (o Org) HasKeySource() (api, app bool) {
+	helper := len(o.CredentialHelper) > 0
+	return o.APIKey != "" || o.APIKeyFile != "" || helper,
+		o.AppKey != "" || o.AppKeyFile != "" || helper
+}
+
+// Reloadable reports whether the organization's keys come from a file or a
+// credential helper, and so may change while the server runs.
+func (o Org) Reloadable() bool {
+	return o.APIKeyFile != "" || o.AppKeyFile != "" || len(o.CredentialHelper) > 0
+}
+
+// Credentials returns the current keys of the organization. Each key comes
+// from the first of its literal value, its key file and the credential
+// helper that is configured.
+func (o Org) Credentials(ctx context.Context) (Credentials, error) {
+	creds := Credentials{APIKey: o.APIKey, AppKey: o.AppKey}
+
+	var err error
+	if creds.APIKey == "" && o.APIKeyFile != "" {
+		if creds.APIKey, err = readKeyFile(o.APIKeyFile); err != nil {
+			return Credentials{}, fmt.Errorf("org %q: API key file: %w", o.Name, err)
+		}
+	}
+	if creds.AppKey == "" && o.AppKeyFile != "" {
+		if creds.AppKey, err = readKeyFile(o.AppKeyFile); err != nil {
+			return Credentials{}, fmt.Errorf("org %q: application key file: %w", o.Name, err)
+		}
+	}
+
+	if (creds.APIKey == "" || creds.AppKey == "") && len(o.CredentialHelper) > 0 {
+		helper, err := runCredentialHelper(ctx, o.Name, o.CredentialHelper)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("org %q: credential helper: %w", o.Name, err)
+		}
+		if creds.APIKey == "" {
+			creds.APIKey = helper.APIKey
+		}
+		if creds.AppKey == "" {
+			creds.AppKey = helper.AppKey
+		}
+	}
+
+	if creds.APIKey == "" {
+		return Credentials{}, fmt.Errorf("org %q: no API key found", o.Name)
+	}
+	if creds.AppKey == "" {
+		return Credentials{}, fmt.Errorf("org %q: no application key found", o.Name)
+	}
+	return creds, nil
+}
+
+// readKeyFile returns the contents of a key file, without surrounding
+// whitespace.
+func readKeyFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	key := strings.TrimSpace(string(b))
+	if key == "" {
+		return "", fmt.Errorf("%s is empty", path)
+	}
+	return key, nil
+}
+
+// runCredentialHelper runs command and decodes the JSON object it prints,
+// e.g. {"api_key": "...", "app_key": "..."}. The organization name is passed
+// in the DD_MCP_ORG environment variable.
+func runCredentialHelper(ctx context.Context, org string, command []string) (Credentials, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialHelperTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "DD_MCP_ORG="+org)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return Credentials{}, fmt.Errorf("%s: %w: %s", command[0], err, msg)
+		}
+		return Credentials{}, fmt.Errorf("%s: %w", command[0], err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		return Credentials{}, fmt.Errorf("%s printed invalid JSON: %w", command[0], err)
+	}
+	if creds.APIKey == "" && creds.AppKey == "" {
+		return Credentials{}, errors.New(command[0] + ` printed no keys; expected {"api_key": "...", "app_key": "..."}`)
+	}
+	return creds, nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeKey writes a key file holding key and returns its path.
+func writeKey(t *testing.T, key string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(path, []byte(key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// helper returns a credential helper command running script with sh.
+func helper(script string) []string {
+	return []string{"sh", "-c", script}
+}
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		name string
+		org  Org
+		want Credentials
+	}{
+		{
+			name: "literal keys",
+			org:  Org{APIKey: "api", AppKey: "app"},
+			want: Credentials{APIKey: "api", AppKey: "app"},
+		},
+		{
+			name: "key files",
+			org:  Org{APIKeyFile: writeKey(t, "file-api\n"), AppKeyFile: writeKey(t, "  file-app  ")},
+			want: Credentials{APIKey: "file-api", AppKey: "file-app"},
+		},
+		{
+			name: "helper",
+			org:  Org{Name: "eu1", CredentialHelper: helper(`printf '{"api_key": "helper-api", "app_key": "%s-app"}' "$DD_MCP_ORG"`)},
+			want: Credentials{APIKey: "helper-api", AppKey: "eu1-app"},
+		},
+		{
+			name: "helper fills the missing key",
+			org:  Org{APIKey: "api", CredentialHelper: helper(`echo '{"api_key": "helper-api", "app_key": "helper-app"}'`)},
+			want: Credentials{APIKey: "api", AppKey: "helper-app"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.org.Credentials(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Credentials() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		org  Org
+		want string
+	}{
+		{"missing key file", Org{APIKeyFile: filepath.Join(t.TempDir(), "nope"), AppKey: "app"}, "API key file"},
+		{"empty key file", Org{APIKey: "api", AppKeyFile: writeKey(t, "\n")}, "is empty"},
+		{"helper fails", Org{CredentialHelper: helper(`echo "not logged in" >&2; exit 1`)}, "not logged in"},
+		{"helper prints invalid JSON", Org{CredentialHelper: helper(`echo api-key`)}, "invalid JSON"},
+		{"helper prints no keys", Org{CredentialHelper: helper(`echo '{}'`)}, "printed no keys"},
+		{"helper omits a key", Org{CredentialHelper: helper(`echo '{"api_key": "api"}'`)}, "no application key found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.org.Credentials(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Credentials() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCredentialHelperTimeout(t *testing.T) {
+	org := Org{CredentialHelper: helper(`exec sleep 10`)}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := org.Credentials(ctx); err == nil {
+		t.Fatal("Credentials() succeeded, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Credentials() returned after %s, want soon after the deadline", elapsed)
+	}
+}
+
+func TestLoadKeyFileOverridesFileKey(t *testing.T) {
+	setEnv(t, map[string]string{"DD_US1_API_KEY_FILE": writeKey(t, "env-file-api")})
+	cfg, err := Load(writeConfig(t, testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	us1, _ := cfg.Org("us1")
+	creds, err := us1.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKey != "env-file-api" || creds.AppKey != "file-app" {
+		t.Errorf("credentials = %+v, want the API key from DD_US1_API_KEY_FILE and the app key from the config file", creds)
+	}
+}
+
+func TestLoadCredentialHelperCommand(t *testing.T) {
+	// A helper in a directory whose name has a space, given as a JSON array.
+	dir := filepath.Join(t.TempDir(), "my tools")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "datadog-keys")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '{\"api_key\": \"%s\", \"app_key\": \"helper-app\"}' \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	setEnv(t, map[string]string{"DD_CREDENTIAL_HELPER": `["` + script + `", "two words"]`})
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, _ := cfg.Org(DefaultOrgName)
+	creds, err := org.Credentials(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.APIKey != "two words" || creds.AppKey != "helper-app" {
+		t.Errorf("credentials = %+v, want the keys printed by the helper", creds)
+	}
+
+	setEnv(t, map[string]string{"DD_CREDENTIAL_HELPER": `["` + script})
+	if _, err := Load(""); err == nil || !strings.Contains(err.Error(), "invalid DD_CREDENTIAL_HELPER") {
+		t.Errorf("Load with a malformed helper = %v, want an invalid DD_CREDENTIAL_HELPER error", err)
+	}
+}
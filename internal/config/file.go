@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig mirrors the layout of the YAML configuration file.
+type fileConfig struct {
+	DefaultOrg  string             `yaml:"default_org"`
+	Orgs        map[string]fileOrg `yaml:"orgs"`
+	Credentials struct {
+		Refresh      time.Duration `yaml:"refresh"`
+		StartupCheck bool          `yaml:"startup_check"`
+	} `yaml:"credentials"`
+	Timeouts struct {
+		Default time.Duration            `yaml:"default"`
+		Tools   map[string]time.Duration `yaml:"tools"`
+	} `yaml:"timeouts"`
+	TimeRanges  map[string]time.Duration `yaml:"time_ranges"`
+	Limits      *Limits                  `yaml:"limits"`
+	Concurrency *fileConcurrency         `yaml:"concurrency"`
+	Tools       struct {
+		Enabled  []string `yaml:"enabled"`
+		Disabled []string `yaml:"disabled"`
+		ReadOnly bool     `yaml:"read_only"`
+	} `yaml:"tools"`
+	Audit struct {
+		File string `yaml:"file"`
+	} `yaml:"audit"`
+	Metrics struct {
+		Listen string `yaml:"listen"`
+	} `yaml:"metrics"`
+}
+
+// fileConcurrency distinguishes an unset global limit from an explicit zero,
+// which lifts the limit.
+type fileConcurrency struct {
+	Global   *int           `yaml:"global"`
+	Families map[string]int `yaml:"families"`
+}
+
+type fileOrg struct {
+	APIKey           string   `yaml:"api_key"`
+	AppKey           string   `yaml:"app_key"`
+	APIKeyFile       string   `yaml:"api_key_file"`
+	AppKeyFile       string   `yaml:"app_key_file"`
+	CredentialHelper []string `yaml:"credential_helper"`
+	Site             string   `yaml:"site"`
+}
+
+// DefaultPath returns the configuration file location following the XDG
+// base directory convention, or "" if no home directory is known.
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "datadog-mcp", "config.yaml")
+}
+
+// loadFile reads the YAML configuration file at path into cfg. Keys the file
+// format does not know about are rejected.
+func loadFile(cfg *Config, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(fc.Orgs))
+	for name := range fc.Orgs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		org := fc.Orgs[name]
+		cfg.Orgs = append(cfg.Orgs, Org{
+			Name:             name,
+			APIKey:           org.APIKey,
+			AppKey:           org.AppKey,
+			Site:             org.Site,
+			APIKeyFile:       org.APIKeyFile,
+			AppKeyFile:       org.AppKeyFile,
+			CredentialHelper: org.CredentialHelper,
+		})
+	}
+	cfg.DefaultOrg = fc.DefaultOrg
+	if fc.Credentials.Refresh != 0 {
+		cfg.CredentialRefresh = fc.Credentials.Refresh
+	}
+	cfg.StartupCheck = fc.Credentials.StartupCheck
+
+	if fc.Timeouts.Default != 0 {
+		cfg.ToolTimeout = fc.Timeouts.Default
+	}
+	for name, d := range fc.Timeouts.Tools {
+		cfg.ToolTimeouts[name] = d
+	}
+	for name, d := range fc.TimeRanges {
+		cfg.Lookbacks[name] = d
+	}
+	if fc.Limits != nil {
+		mergeLimits(&cfg.Limits, *fc.Limits)
+	}
+	if fc.Concurrency != nil {
+		if fc.Concurrency.Global != nil {
+			cfg.Concurrency.Global = *fc.Concurrency.Global
+		}
+		for family, n := range fc.Concurrency.Families {
+			cfg.Concurrency.Families[family] = n
+		}
+	}
+	cfg.EnabledTools = fc.Tools.Enabled
+	cfg.DisabledTools = fc.Tools.Disabled
+	cfg.ReadOnly = fc.Tools.ReadOnly
+	cfg.AuditLog = fc.Audit.File
+	cfg.MetricsListen = fc.Metrics.Listen
+
+	return nil
+}
+
+// mergeLimits overrides the limits in dst that are set in src.
+func mergeLimits(dst *Limits, src Limits) {
+	if src.MaxSeries != 0 {
+		dst.MaxSeries = src.MaxSeries
+	}
+	if src.MaxDataPoints != 0 {
+		dst.MaxDataPoints = src.MaxDataPoints
+	}
+	if src.MaxSpans != 0 {
+		dst.MaxSpans = src.MaxSpans
+	}
+	if src.MaxDashboards != 0 {
+		dst.MaxDashboards = src.MaxDashboards
+	}
+	if src.MaxMetrics != 0 {
+		dst.MaxMetrics = src.MaxMetrics
+	}
+	if src.MaxOutputChars != 0 {
+		dst.MaxOutputChars = src.MaxOutputChars
+	}
+}
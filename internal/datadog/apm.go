@@ -2,7 +2,6 @@ package datadog
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
@@ -11,35 +10,31 @@ import (
 
 // Span represents an APM span.
 type Span struct {
-	TraceID    string            `json:"trace_id"`
-	SpanID     string            `json:"span_id"`
-	ParentID   string            `json:"parent_id,omitempty"`
-	Service    string            `json:"service"`
-	Name       string            `json:"name"`
-	Resource   string            `json:"resource"`
-	Type       string            `json:"type,omitempty"`
-	Start      time.Time         `json:"start"`
-	Duration   int64             `json:"duration_ns"`
-	Status     string            `json:"status"`
-	Error      int32             `json:"error"`
-	Tags       map[string]string `json:"tags,omitempty"`
+	TraceID  string            `json:"trace_id"`
+	SpanID   string            `json:"span_id"`
+	ParentID string            `json:"parent_id,omitempty"`
+	Service  string            `json:"service"`
+	Name     string            `json:"name"`
+	Resource string            `json:"resource"`
+	Type     string            `json:"type,omitempty"`
+	Start    time.Time         `json:"start"`
+	Duration int64             `json:"duration_ns"`
+	Status   string            `json:"status"`
+	Error    int32             `json:"error"`
+	Tags     map[string]string `json:"tags,omitempty"`
 }
 
 // QuerySpansResult contains the result of a spans query.
 type QuerySpansResult struct {
-	Spans      []Span `json:"spans"`
-	TotalCount int    `json:"total_count"`
-	NextCursor string `json:"next_cursor,omitempty"`
+	Spans      []Span    `json:"spans"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	TotalCount int       `json:"total_count"`
+	NextCursor string    `json:"next_cursor,omitempty"`
 }
 
-// QuerySpans queries APM spans from Datadog.
-func (c *Client) QuerySpans(ctx context.Context, query string, from, to string, limit int32, cursor string) (*QuerySpansResult, error) {
-	if from == "" {
-		from = "now-15m"
-	}
-	if to == "" {
-		to = "now"
-	}
+// QuerySpans queries APM spans starting between from and to from Datadog.
+func (c *Client) QuerySpans(ctx context.Context, query string, from, to time.Time, limit int32, cursor string) (*QuerySpansResult, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -58,9 +53,9 @@ func (c *Client) QuerySpans(ctx context.Context, query string, from, to string,
 		Data: &datadogV2.SpansListRequestData{
 			Attributes: &datadogV2.SpansListRequestAttributes{
 				Filter: &datadogV2.SpansQueryFilter{
-					From:  datadog.PtrString(from),
+					From:  datadog.PtrString(from.UTC().Format(time.RFC3339)),
 					Query: datadog.PtrString(query),
-					To:    datadog.PtrString(to),
+					To:    datadog.PtrString(to.UTC().Format(time.RFC3339)),
 				},
 				Options: &datadogV2.SpansQueryOptions{
 					Timezone: datadog.PtrString("UTC"),
@@ -72,13 +67,15 @@ func (c *Client) QuerySpans(ctx context.Context, query string, from, to string,
 		},
 	}
 
-	resp, _, err := c.spansAPI.ListSpans(c.ctx, body)
+	resp, httpResp, err := c.spansAPI.ListSpans(markRead(c.Context(ctx)), body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query spans: %w", err)
+		return nil, apiError("failed to query spans", httpResp, err)
 	}
 
 	result := &QuerySpansResult{
 		Spans: make([]Span, 0),
+		From:  from,
+		To:    to,
 	}
 
 	if resp.Data != nil {
@@ -0,0 +1,23 @@
+package datadog
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is the set of Datadog operations the MCP tools depend on.
+// Client implements it against the Datadog API; the fake package provides an
+// in-memory implementation for running tools offline.
+type Backend interface {
+	QueryMetrics(ctx context.Context, query string, from, to time.Time) (*QueryMetricsResult, error)
+	ListMetrics(ctx context.Context, from time.Time, host string, tagFilter string) (*ListMetricsResult, error)
+	GetMetricMetadata(ctx context.Context, metric string) (*MetricMetadata, error)
+	ListMetricTags(ctx context.Context, metric string) (*MetricTags, error)
+	QueryTimeseries(ctx context.Context, queries []TimeseriesQuery, formulas []string, from, to time.Time, interval time.Duration) (*QueryTimeseriesResult, error)
+	QuerySpans(ctx context.Context, query string, from, to time.Time, limit int32, cursor string) (*QuerySpansResult, error)
+	ListServices(ctx context.Context) (*ListServicesResult, error)
+	ListDashboards(ctx context.Context, filterShared, filterDeleted bool, limit, start int64) (*ListDashboardsResult, error)
+	GetDashboard(ctx context.Context, dashboardID string) (*Dashboard, error)
+}
+
+var _ Backend = (*Client)(nil)
@@ -0,0 +1,171 @@
+package datadog
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+// DefaultCacheSize bounds the number of cached responses.
+const DefaultCacheSize = 256
+
+// DefaultCacheTTLs holds how long responses of each cached Client method stay
+// fresh. Methods not listed here are never cached.
+var DefaultCacheTTLs = map[string]time.Duration{
+	"ListMetrics":       10 * time.Minute,
+	"GetMetricMetadata": 10 * time.Minute,
+	"ListMetricTags":    5 * time.Minute,
+	"ListServices":      5 * time.Minute,
+	"ListDashboards":    time.Minute,
+	"GetDashboard":      time.Minute,
+}
+
+type noCacheKey struct{}
+
+// WithoutCache returns a context whose calls bypass cached responses. Fresh
+// responses are still stored, so later calls benefit from the refresh.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(noCacheKey{}).(bool)
+	return bypass
+}
+
+// responseCache is a size-bounded LRU cache of API responses with per-method
+// TTLs. Concurrent identical calls share a single upstream request.
+type responseCache struct {
+	org        string
+	maxEntries int
+	ttls       map[string]time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	group singleflight.Group
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func newResponseCache(org string, maxEntries int, ttls map[string]time.Duration) *responseCache {
+	return &responseCache{
+		org:        org,
+		maxEntries: maxEntries,
+		ttls:       ttls,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// ttl returns the TTL of method, or zero if its responses are not cached.
+func (rc *responseCache) ttl(method string) time.Duration {
+	if rc == nil || rc.maxEntries <= 0 {
+		return 0
+	}
+	return rc.ttls[method]
+}
+
+func (rc *responseCache) get(key string) (any, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if time.Now().After(entry.expires) {
+		rc.lru.Remove(elem)
+		delete(rc.entries, key)
+		return nil, false
+	}
+	rc.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+func (rc *responseCache) set(key string, value any, ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry := &cacheEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if elem, ok := rc.entries[key]; ok {
+		elem.Value = entry
+		rc.lru.MoveToFront(elem)
+		return
+	}
+	rc.entries[key] = rc.lru.PushFront(entry)
+
+	for rc.lru.Len() > rc.maxEntries {
+		oldest := rc.lru.Back()
+		rc.lru.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cacheKey builds a cache key from a method name and its normalized arguments.
+func cacheKey(method string, args ...string) string {
+	for i, arg := range args {
+		args[i] = strings.TrimSpace(arg)
+	}
+	return method + "\x00" + strings.Join(args, "\x00")
+}
+
+// cached returns the cached response for key if it is fresh, and otherwise
+// calls fetch, sharing its result with any identical calls in flight.
+//
+// The returned value is a shallow copy of the cached response: callers may
+// reassign its fields but must not modify the contents of its slices or maps.
+func cached[T any](ctx context.Context, rc *responseCache, method, key string, fetch func(context.Context) (*T, error)) (*T, error) {
+	ttl := rc.ttl(method)
+	if ttl <= 0 {
+		return fetch(ctx)
+	}
+
+	if cacheBypassed(ctx) {
+		telemetry.CacheRequests.WithLabelValues(rc.org, method, "bypass").Inc()
+	} else if v, ok := rc.get(key); ok {
+		telemetry.CacheRequests.WithLabelValues(rc.org, method, "hit").Inc()
+		clone := *v.(*T)
+		return &clone, nil
+	} else {
+		telemetry.CacheRequests.WithLabelValues(rc.org, method, "miss").Inc()
+	}
+
+	ch := rc.group.DoChan(key, func() (any, error) {
+		v, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		rc.set(key, v, ttl)
+		return v, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			// The shared call ran on the context of whichever caller started
+			// it. If that caller went away, retry on our own context.
+			if ctx.Err() == nil && (errors.Is(res.Err, context.Canceled) || errors.Is(res.Err, context.DeadlineExceeded)) {
+				return fetch(ctx)
+			}
+			return nil, res.Err
+		}
+		clone := *res.Val.(*T)
+		return &clone, nil
+	}
+}
@@ -0,0 +1,151 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type cachedValue struct {
+	N int
+}
+
+// counter returns a fetch function numbering its calls, and the number of
+// calls so far.
+func counter() (func(context.Context) (*cachedValue, error), func() int) {
+	var n atomic.Int32
+	fetch := func(context.Context) (*cachedValue, error) {
+		return &cachedValue{N: int(n.Add(1))}, nil
+	}
+	return fetch, func() int { return int(n.Load()) }
+}
+
+func TestCacheTTL(t *testing.T) {
+	rc := newResponseCache("test", 8, map[string]time.Duration{"Get": 50 * time.Millisecond})
+	fetch, calls := counter()
+	ctx := context.Background()
+
+	for range 2 {
+		if v, err := cached(ctx, rc, "Get", "k", fetch); err != nil || v.N != 1 {
+			t.Fatalf("cached = %v, %v, want the first response", v, err)
+		}
+	}
+	time.Sleep(60 * time.Millisecond)
+	if v, _ := cached(ctx, rc, "Get", "k", fetch); v.N != 2 {
+		t.Errorf("after the TTL, cached = %v, want a fresh response", v)
+	}
+
+	// Methods without a TTL are never cached.
+	cached(ctx, rc, "List", "k", fetch)
+	cached(ctx, rc, "List", "k", fetch)
+	if n := calls(); n != 4 {
+		t.Errorf("got %d fetches, want 4", n)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	rc := newResponseCache("test", 2, map[string]time.Duration{"Get": time.Minute})
+	fetch, calls := counter()
+	ctx := context.Background()
+
+	cached(ctx, rc, "Get", "a", fetch)
+	cached(ctx, rc, "Get", "b", fetch)
+	cached(ctx, rc, "Get", "a", fetch) // a is now more recent than b
+	cached(ctx, rc, "Get", "c", fetch) // evicts b
+	if n := calls(); n != 3 {
+		t.Fatalf("got %d fetches, want 3", n)
+	}
+
+	if v, _ := cached(ctx, rc, "Get", "a", fetch); v.N != 1 {
+		t.Errorf("a = %v, want it still cached", v)
+	}
+	if v, _ := cached(ctx, rc, "Get", "b", fetch); v.N != 4 {
+		t.Errorf("b = %v, want it refetched", v)
+	}
+}
+
+func TestCacheSharesConcurrentCalls(t *testing.T) {
+	rc := newResponseCache("test", 8, map[string]time.Duration{"Get": time.Minute})
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	fetch := func(context.Context) (*cachedValue, error) {
+		fetches.Add(1)
+		<-release
+		return &cachedValue{N: 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := cached(context.Background(), rc, "Get", "k", fetch); err != nil || v.N != 1 {
+				t.Errorf("cached = %v, %v, want the shared response", v, err)
+			}
+		}()
+	}
+	// Let every caller join the call in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("got %d fetches, want 1", n)
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	rc := newResponseCache("test", 8, map[string]time.Duration{"Get": time.Minute})
+	fetch, _ := counter()
+	ctx := context.Background()
+
+	cached(ctx, rc, "Get", "k", fetch)
+	if v, _ := cached(WithoutCache(ctx), rc, "Get", "k", fetch); v.N != 2 {
+		t.Errorf("bypassing the cache = %v, want a fresh response", v)
+	}
+	// The refreshed response replaces the cached one.
+	if v, _ := cached(ctx, rc, "Get", "k", fetch); v.N != 2 {
+		t.Errorf("after a bypass, cached = %v, want the refreshed response", v)
+	}
+}
+
+func TestCacheRetriesWhenLeaderCancelled(t *testing.T) {
+	rc := newResponseCache("test", 8, map[string]time.Duration{"Get": time.Minute})
+	started := make(chan struct{})
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cached(leaderCtx, rc, "Get", "k", func(ctx context.Context) (*cachedValue, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		leaderErr <- err
+	}()
+	<-started
+
+	followerDone := make(chan struct{})
+	var v *cachedValue
+	var err error
+	go func() {
+		defer close(followerDone)
+		v, err = cached(context.Background(), rc, "Get", "k", func(context.Context) (*cachedValue, error) {
+			return &cachedValue{N: 2}, nil
+		})
+	}()
+	// Let the follower join the leader's call before cancelling it.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-leaderErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("leader err = %v, want %v", err, context.Canceled)
+	}
+	<-followerDone
+	if err != nil || v.N != 2 {
+		t.Errorf("follower = %v, %v, want its own response", v, err)
+	}
+}
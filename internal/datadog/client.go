@@ -2,6 +2,9 @@ package datadog
 
 import (
 	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
@@ -12,51 +15,124 @@ import (
 
 // Client wraps the Datadog API client with authentication.
 type Client struct {
-	apiClient     *datadog.APIClient
-	metricsV1     *datadogV1.MetricsApi
-	metricsV2     *datadogV2.MetricsApi
-	spansAPI      *datadogV2.SpansApi
-	serviceAPI    *datadogV2.ServiceDefinitionApi
-	dashboardsAPI *datadogV1.DashboardsApi
-	ctx           context.Context
+	apiClient       *datadog.APIClient
+	metricsV1       *datadogV1.MetricsApi
+	metricsV2       *datadogV2.MetricsApi
+	spansAPI        *datadogV2.SpansApi
+	serviceAPI      *datadogV2.ServiceDefinitionApi
+	dashboardsAPI   *datadogV1.DashboardsApi
+	cache           *responseCache
+	apiKeys         atomic.Pointer[map[string]datadog.APIKey]
+	serverVariables map[string]string
 }
 
-// NewClient creates a new Datadog API client with the given configuration.
-func NewClient(cfg *config.Config) *Client {
-	ctx := context.WithValue(
-		context.Background(),
-		datadog.ContextAPIKeys,
-		map[string]datadog.APIKey{
-			"apiKeyAuth": {Key: cfg.APIKey},
-			"appKeyAuth": {Key: cfg.AppKey},
-		},
-	)
-
-	if cfg.Site != "datadoghq.com" {
-		ctx = context.WithValue(
-			ctx,
-			datadog.ContextServerVariables,
-			map[string]string{"site": cfg.Site},
-		)
+// Option customizes a Client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	baseURL   string
+	transport http.RoundTripper
+	cacheSize int
+	cacheTTLs map[string]time.Duration
+	limits    config.Concurrency
+}
+
+// WithBaseURL sends API requests to baseURL instead of the configured
+// Datadog site, e.g. to point the client at a local test server.
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithTransport sets the HTTP transport used to reach Datadog.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithCache bounds the response cache to maxEntries and sets the TTL of each
+// cached method. A maxEntries of zero disables caching.
+func WithCache(maxEntries int, ttls map[string]time.Duration) Option {
+	return func(o *clientOptions) {
+		o.cacheSize = maxEntries
+		o.cacheTTLs = ttls
+	}
+}
+
+// WithConcurrency bounds how many requests the client sends to Datadog at
+// once, overall and per endpoint family. Requests beyond the limits are
+// queued and served fairly between sessions (see WithSession).
+func WithConcurrency(limits config.Concurrency) Option {
+	return func(o *clientOptions) {
+		o.limits = limits
+	}
+}
+
+// NewClient creates a new Datadog API client for the given organization.
+func NewClient(org config.Org, opts ...Option) *Client {
+	options := clientOptions{
+		transport: http.DefaultTransport,
+		cacheSize: DefaultCacheSize,
+		cacheTTLs: DefaultCacheTTLs,
+		limits:    config.DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(&options)
 	}
 
 	configuration := datadog.NewConfiguration()
+	retry := newRetryTransport(newLimitTransport(
+		&traceTransport{next: options.transport, org: org.Name},
+		org.Name,
+		options.limits.Global,
+		options.limits.Families,
+	))
+	// Replayed responses come back at once; waiting out the rate limits and
+	// backoffs they recorded would only slow replay down.
+	_, retry.noWait = options.transport.(*replayTransport)
+	configuration.HTTPClient = &http.Client{Transport: retry}
+	if options.baseURL != "" {
+		configuration.Servers = datadog.ServerConfigurations{{URL: options.baseURL}}
+	}
 	apiClient := datadog.NewAPIClient(configuration)
 
-	return &Client{
+	c := &Client{
 		apiClient:     apiClient,
 		metricsV1:     datadogV1.NewMetricsApi(apiClient),
 		metricsV2:     datadogV2.NewMetricsApi(apiClient),
 		spansAPI:      datadogV2.NewSpansApi(apiClient),
 		serviceAPI:    datadogV2.NewServiceDefinitionApi(apiClient),
 		dashboardsAPI: datadogV1.NewDashboardsApi(apiClient),
-		ctx:           ctx,
+		cache:         newResponseCache(org.Name, options.cacheSize, options.cacheTTLs),
 	}
+	c.SetCredentials(config.Credentials{APIKey: org.APIKey, AppKey: org.AppKey})
+
+	if org.Site != "datadoghq.com" {
+		c.serverVariables = map[string]string{"site": org.Site}
+	}
+
+	return c
 }
 
-// Context returns the authenticated context for API calls.
-func (c *Client) Context() context.Context {
-	return c.ctx
+// SetCredentials replaces the keys used by subsequent requests, e.g. after
+// an application key was rotated.
+func (c *Client) SetCredentials(creds config.Credentials) {
+	c.apiKeys.Store(&map[string]datadog.APIKey{
+		"apiKeyAuth": {Key: creds.APIKey},
+		"appKeyAuth": {Key: creds.AppKey},
+	})
+}
+
+// Context returns ctx with the client's credentials and site layered on top,
+// so that cancellation and deadlines of ctx still apply to the API call.
+func (c *Client) Context(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, datadog.ContextAPIKeys, *c.apiKeys.Load())
+	if c.serverVariables != nil {
+		ctx = context.WithValue(ctx, datadog.ContextServerVariables, c.serverVariables)
+	}
+	return ctx
 }
 
 // MetricsV1 returns the V1 Metrics API.
@@ -0,0 +1,30 @@
+package datadog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+)
+
+func TestClientContext(t *testing.T) {
+	c := NewClient(config.Org{APIKey: "api", AppKey: "app", Site: "datadoghq.eu"})
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := c.Context(parent)
+
+	keys, _ := ctx.Value(datadog.ContextAPIKeys).(map[string]datadog.APIKey)
+	if keys["apiKeyAuth"].Key != "api" || keys["appKeyAuth"].Key != "app" {
+		t.Errorf("API keys = %v, want the configured keys", keys)
+	}
+	if vars, _ := ctx.Value(datadog.ContextServerVariables).(map[string]string); vars["site"] != "datadoghq.eu" {
+		t.Errorf("server variables = %v, want site datadoghq.eu", vars)
+	}
+
+	cancel()
+	if ctx.Err() == nil {
+		t.Error("cancelling the caller's context did not cancel the API context")
+	}
+}
@@ -2,6 +2,7 @@ package datadog
 
 import (
 	"context"
+	"strconv"
 	"time"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
@@ -9,15 +10,15 @@ import (
 
 // DashboardSummary represents a summary of a dashboard.
 type DashboardSummary struct {
-	ID          string    `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description,omitempty"`
-	LayoutType  string    `json:"layout_type"`
-	URL         string    `json:"url,omitempty"`
-	AuthorHandle string   `json:"author_handle,omitempty"`
-	CreatedAt   time.Time `json:"created_at,omitempty"`
-	ModifiedAt  time.Time `json:"modified_at,omitempty"`
-	IsReadOnly  bool      `json:"is_read_only"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	LayoutType   string    `json:"layout_type"`
+	URL          string    `json:"url,omitempty"`
+	AuthorHandle string    `json:"author_handle,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	ModifiedAt   time.Time `json:"modified_at,omitempty"`
+	IsReadOnly   bool      `json:"is_read_only"`
 }
 
 // ListDashboardsResult contains the result of listing dashboards.
@@ -36,10 +37,10 @@ type DashboardWidget struct {
 
 // DashboardTemplateVariable represents a template variable.
 type DashboardTemplateVariable struct {
-	Name             string   `json:"name"`
-	Prefix           string   `json:"prefix,omitempty"`
-	Default          string   `json:"default,omitempty"`
-	AvailableValues  []string `json:"available_values,omitempty"`
+	Name            string   `json:"name"`
+	Prefix          string   `json:"prefix,omitempty"`
+	Default         string   `json:"default,omitempty"`
+	AvailableValues []string `json:"available_values,omitempty"`
 }
 
 // Dashboard represents a full dashboard configuration.
@@ -62,6 +63,15 @@ type Dashboard struct {
 
 // ListDashboards retrieves dashboards from Datadog with pagination support.
 func (c *Client) ListDashboards(ctx context.Context, filterShared, filterDeleted bool, limit, start int64) (*ListDashboardsResult, error) {
+	key := cacheKey("ListDashboards",
+		strconv.FormatBool(filterShared), strconv.FormatBool(filterDeleted),
+		strconv.FormatInt(limit, 10), strconv.FormatInt(start, 10))
+	return cached(ctx, c.cache, "ListDashboards", key, func(ctx context.Context) (*ListDashboardsResult, error) {
+		return c.listDashboards(ctx, filterShared, filterDeleted, limit, start)
+	})
+}
+
+func (c *Client) listDashboards(ctx context.Context, filterShared, filterDeleted bool, limit, start int64) (*ListDashboardsResult, error) {
 	opts := datadogV1.NewListDashboardsOptionalParameters()
 	if filterShared {
 		opts = opts.WithFilterShared(filterShared)
@@ -76,9 +86,9 @@ func (c *Client) ListDashboards(ctx context.Context, filterShared, filterDeleted
 		opts = opts.WithStart(start)
 	}
 
-	resp, _, err := c.dashboardsAPI.ListDashboards(c.ctx, *opts)
+	resp, httpResp, err := c.dashboardsAPI.ListDashboards(c.Context(ctx), *opts)
 	if err != nil {
-		return nil, err
+		return nil, apiError("failed to list dashboards", httpResp, err)
 	}
 
 	result := &ListDashboardsResult{
@@ -136,9 +146,15 @@ func (c *Client) ListDashboards(ctx context.Context, filterShared, filterDeleted
 
 // GetDashboard retrieves a specific dashboard by ID.
 func (c *Client) GetDashboard(ctx context.Context, dashboardID string) (*Dashboard, error) {
-	resp, _, err := c.dashboardsAPI.GetDashboard(c.ctx, dashboardID)
+	return cached(ctx, c.cache, "GetDashboard", cacheKey("GetDashboard", dashboardID), func(ctx context.Context) (*Dashboard, error) {
+		return c.getDashboard(ctx, dashboardID)
+	})
+}
+
+func (c *Client) getDashboard(ctx context.Context, dashboardID string) (*Dashboard, error) {
+	resp, httpResp, err := c.dashboardsAPI.GetDashboard(c.Context(ctx), dashboardID)
 	if err != nil {
-		return nil, err
+		return nil, apiError("failed to get dashboard", httpResp, err)
 	}
 
 	dashboard := &Dashboard{
@@ -0,0 +1,102 @@
+package datadog
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+)
+
+// CheckAPIKey names the check validating the API key alone.
+const CheckAPIKey = "api_key"
+
+// Application key scopes the tools need, as Datadog names them.
+const (
+	ScopeTimeseriesQuery    = "timeseries_query"
+	ScopeMetricsRead        = "metrics_read"
+	ScopeAPMRead            = "apm_read"
+	ScopeServiceCatalogRead = "apm_service_catalog_read"
+	ScopeDashboardsRead     = "dashboards_read"
+)
+
+// Check is the outcome of one credential check against the Datadog API.
+type Check struct {
+	// Name is CheckAPIKey or the application key scope the check exercised.
+	Name string
+	// Status is the HTTP status Datadog answered with, or 0 if the request
+	// did not get an answer.
+	Status int
+	// Err is nil when the check passed.
+	Err error
+}
+
+// Denied reports whether Datadog rejected the credentials, as opposed to the
+// check failing for another reason such as a network error.
+func (c Check) Denied() bool {
+	return c.Status == http.StatusUnauthorized || c.Status == http.StatusForbidden
+}
+
+// Diagnose validates the client's API key, then makes one minimal request
+// needing each application key scope the tools use, to verify that the key
+// has it. It bypasses the response cache.
+func (c *Client) Diagnose(ctx context.Context) []Check {
+	ctx = c.Context(ctx)
+	now := time.Now()
+
+	probes := []struct {
+		name string
+		call func() (*http.Response, error)
+	}{
+		{CheckAPIKey, func() (*http.Response, error) {
+			_, resp, err := datadogV1.NewAuthenticationApi(c.apiClient).Validate(ctx)
+			return resp, err
+		}},
+		{ScopeTimeseriesQuery, func() (*http.Response, error) {
+			_, resp, err := c.metricsV1.QueryMetrics(ctx, now.Add(-5*time.Minute).Unix(), now.Unix(), "avg:datadog.estimated_usage.hosts{*}")
+			return resp, err
+		}},
+		{ScopeMetricsRead, func() (*http.Response, error) {
+			// A host that does not exist keeps the answer empty.
+			_, resp, err := c.metricsV1.ListActiveMetrics(ctx, now.Add(-5*time.Minute).Unix(), *datadogV1.NewListActiveMetricsOptionalParameters().
+				WithHost("datadog-mcp-doctor"))
+			return resp, err
+		}},
+		{ScopeAPMRead, func() (*http.Response, error) {
+			_, resp, err := c.spansAPI.ListSpansGet(ctx, *datadogV2.NewListSpansGetOptionalParameters().
+				WithFilterFrom("now-1m").
+				WithPageLimit(1))
+			return resp, err
+		}},
+		{ScopeServiceCatalogRead, func() (*http.Response, error) {
+			_, resp, err := c.serviceAPI.ListServiceDefinitions(ctx, *datadogV2.NewListServiceDefinitionsOptionalParameters().
+				WithPageSize(1))
+			return resp, err
+		}},
+		{ScopeDashboardsRead, func() (*http.Response, error) {
+			_, resp, err := c.dashboardsAPI.ListDashboards(ctx, *datadogV1.NewListDashboardsOptionalParameters().
+				WithCount(1))
+			return resp, err
+		}},
+	}
+
+	checks := make([]Check, 0, len(probes))
+	for _, probe := range probes {
+		resp, err := probe.call()
+		check := Check{Name: probe.name}
+		if resp != nil {
+			check.Status = resp.StatusCode
+		}
+		if err != nil {
+			check.Err = apiError("request failed", resp, err)
+		}
+		checks = append(checks, check)
+
+		if probe.name == CheckAPIKey && check.Denied() {
+			// Every other request would be refused for the same reason.
+			break
+		}
+	}
+	return checks
+}
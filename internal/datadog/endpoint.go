@@ -0,0 +1,63 @@
+package datadog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Endpoint families group API endpoints that share a concurrency limit.
+const (
+	FamilyMetrics    = "metrics"
+	FamilySpans      = "spans"
+	FamilyDashboards = "dashboards"
+	FamilyCatalog    = "catalog"
+	FamilyOther      = "other"
+)
+
+// familyPrefixes maps API path prefixes to their endpoint family.
+var familyPrefixes = []struct {
+	prefix string
+	family string
+}{
+	{"/api/v1/query", FamilyMetrics},
+	{"/api/v1/metrics", FamilyMetrics},
+	{"/api/v2/metrics", FamilyMetrics},
+	{"/api/v2/query/", FamilyMetrics},
+	{"/api/v2/spans", FamilySpans},
+	{"/api/v1/dashboard", FamilyDashboards},
+	{"/api/v2/services", FamilyCatalog},
+}
+
+// routes maps request paths that embed identifiers to a fixed template, so
+// that per-endpoint metrics stay low-cardinality.
+var routes = []struct {
+	pattern  *regexp.Regexp
+	template string
+}{
+	{regexp.MustCompile(`^/api/v1/dashboard/[^/]+$`), "/api/v1/dashboard/{dashboard_id}"},
+	{regexp.MustCompile(`^/api/v1/metrics/[^/]+$`), "/api/v1/metrics/{metric_name}"},
+	{regexp.MustCompile(`^/api/v2/metrics/[^/]+/([^/]+)$`), "/api/v2/metrics/{metric_name}/$1"},
+	{regexp.MustCompile(`^/api/v2/services/definitions/[^/]+$`), "/api/v2/services/definitions/{service_name}"},
+}
+
+// Route returns the route template of an API request path, e.g.
+// "/api/v1/dashboard/{dashboard_id}" for "/api/v1/dashboard/abc-123-def".
+func Route(path string) string {
+	for _, r := range routes {
+		if r.pattern.MatchString(path) {
+			return r.pattern.ReplaceAllString(path, r.template)
+		}
+	}
+	return path
+}
+
+// Family returns the endpoint family of an API request path, or FamilyOther
+// for endpoints outside the known families.
+func Family(path string) string {
+	for _, f := range familyPrefixes {
+		if strings.HasPrefix(path, f.prefix) {
+			return f.family
+		}
+	}
+	return FamilyOther
+}
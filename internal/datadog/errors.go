@@ -0,0 +1,168 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+)
+
+// ErrorKind classifies why a Datadog API call failed.
+type ErrorKind string
+
+const (
+	// ErrorAuth means Datadog rejected the API or application key (401).
+	ErrorAuth ErrorKind = "auth"
+	// ErrorPermission means the keys are valid but may not use the
+	// endpoint (403).
+	ErrorPermission ErrorKind = "permission"
+	// ErrorRateLimited means the org's rate limit for the endpoint is used
+	// up (429).
+	ErrorRateLimited ErrorKind = "rate_limited"
+	// ErrorInvalidQuery means Datadog rejected the request itself, usually
+	// because of a malformed query (400, 422).
+	ErrorInvalidQuery ErrorKind = "invalid_query"
+	// ErrorNotFound means the requested object does not exist (404).
+	ErrorNotFound ErrorKind = "not_found"
+	// ErrorUnavailable means Datadog could not be reached or failed to
+	// answer (5xx or a network error).
+	ErrorUnavailable ErrorKind = "unavailable"
+	// ErrorOther covers any other failure.
+	ErrorOther ErrorKind = "other"
+)
+
+// APIError describes a failed Datadog API call.
+type APIError struct {
+	// Op describes the failed operation, e.g. "failed to query metrics".
+	Op string
+	// Status is the HTTP status Datadog answered with, or 0 if the request
+	// got no answer.
+	Status int
+	// Kind classifies the failure.
+	Kind ErrorKind
+	// Messages holds the error messages from Datadog's response body.
+	Messages []string
+	// RateLimit is the rate limit state reported with the response, if any.
+	RateLimit *RateLimit
+	// Err is the underlying error returned by the SDK.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	if e.Status != 0 {
+		fmt.Fprintf(&b, ": Datadog returned %d %s", e.Status, http.StatusText(e.Status))
+		if len(e.Messages) > 0 {
+			b.WriteString(": " + strings.Join(e.Messages, "; "))
+		}
+	} else {
+		b.WriteString(": " + e.Err.Error())
+	}
+	if e.RateLimit != nil {
+		fmt.Fprintf(&b, " (%s)", e.RateLimit)
+	}
+	return b.String()
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// NewAPIError returns an error of the given kind, as if Datadog had answered
+// a request with status. It is meant for fakes and tests.
+func NewAPIError(op string, status int, messages ...string) *APIError {
+	return &APIError{
+		Op:       op,
+		Status:   status,
+		Kind:     kindOf(status),
+		Messages: messages,
+		Err:      errors.New(http.StatusText(status)),
+	}
+}
+
+// apiError wraps an error returned by the Datadog SDK into an *APIError,
+// with the status, messages and rate limit of the failed response. Errors
+// caused by the caller's context are wrapped as they are.
+func apiError(msg string, resp *http.Response, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s: %w", msg, err)
+	}
+
+	e := &APIError{Op: msg, Kind: ErrorUnavailable, Err: err}
+	if resp != nil {
+		e.Status = resp.StatusCode
+		e.Kind = kindOf(resp.StatusCode)
+	}
+	if rl, ok := parseRateLimit(resp); ok {
+		e.RateLimit = &rl
+	}
+
+	var openAPIErr datadog.GenericOpenAPIError
+	if errors.As(err, &openAPIErr) {
+		e.Messages = errorMessages(openAPIErr.Body())
+	}
+	return e
+}
+
+// kindOf classifies an HTTP status.
+func kindOf(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrorAuth
+	case status == http.StatusForbidden:
+		return ErrorPermission
+	case status == http.StatusTooManyRequests:
+		return ErrorRateLimited
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return ErrorInvalidQuery
+	case status == http.StatusNotFound:
+		return ErrorNotFound
+	case status >= 500:
+		return ErrorUnavailable
+	default:
+		return ErrorOther
+	}
+}
+
+// errorMessages extracts the messages of a Datadog error response. The v1
+// API answers {"errors": ["..."]}, the v2 API uses JSON:API error objects.
+func errorMessages(body []byte) []string {
+	var payload struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		if s := strings.TrimSpace(string(body)); s != "" && len(s) < 500 && !strings.HasPrefix(s, "<") {
+			return []string{s}
+		}
+		return nil
+	}
+
+	var messages []string
+	for _, raw := range payload.Errors {
+		var s string
+		if json.Unmarshal(raw, &s) == nil {
+			messages = append(messages, strings.Join(strings.Fields(s), " "))
+			continue
+		}
+		var obj struct {
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		}
+		if json.Unmarshal(raw, &obj) == nil {
+			switch {
+			case obj.Detail != "" && obj.Title != "":
+				messages = append(messages, obj.Title+": "+obj.Detail)
+			case obj.Detail != "":
+				messages = append(messages, obj.Detail)
+			case obj.Title != "":
+				messages = append(messages, obj.Title)
+			}
+		}
+	}
+	return messages
+}
@@ -0,0 +1,65 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		kind     ErrorKind
+		messages []string
+	}{
+		{"forbidden", http.StatusForbidden, `{"errors": ["Forbidden"]}`, ErrorPermission, []string{"Forbidden"}},
+		{"unauthorized", http.StatusUnauthorized, `{"errors": ["Unauthorized"]}`, ErrorAuth, []string{"Unauthorized"}},
+		{"not found", http.StatusNotFound, `{"errors": ["Dashboard abc not found"]}`, ErrorNotFound, []string{"Dashboard abc not found"}},
+		{"v2 invalid query", http.StatusBadRequest, `{"errors": [{"title": "Bad Request", "detail": "Rule 'scope_expr' didn't match at 'by {'"}]}`, ErrorInvalidQuery, []string{"Bad Request: Rule 'scope_expr' didn't match at 'by {'"}},
+		{"plain body", http.StatusUnprocessableEntity, "query too long", ErrorInvalidQuery, []string{"query too long"}},
+		{"html body", http.StatusConflict, "<html>conflict</html>", ErrorOther, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.GetDashboard(context.Background(), "abc")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want an *APIError", err)
+			}
+			if apiErr.Status != tt.status || apiErr.Kind != tt.kind {
+				t.Errorf("Status, Kind = %d, %q; want %d, %q", apiErr.Status, apiErr.Kind, tt.status, tt.kind)
+			}
+			if !reflect.DeepEqual(apiErr.Messages, tt.messages) {
+				t.Errorf("Messages = %q, want %q", apiErr.Messages, tt.messages)
+			}
+			if want := fmt.Sprintf("failed to get dashboard: Datadog returned %d %s", tt.status, http.StatusText(tt.status)); !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("Error() = %q, want it to start with %q", err, want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorContext(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with a canceled context")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.GetDashboard(ctx, "abc")
+	var apiErr *APIError
+	if errors.As(err, &apiErr) || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want the context error unclassified", err)
+	}
+}
@@ -0,0 +1,343 @@
+// Package fake provides an in-memory datadog.Backend seeded with metrics,
+// spans, services and dashboards, for exercising the MCP tools offline.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// Series seeds the response to a metrics query. Values are spread evenly
+// across the requested time range, so results are deterministic for a given
+// range regardless of when the query runs.
+type Series struct {
+	Query  string
+	Metric string
+	Tags   []string
+	Unit   string
+	Values []float64
+}
+
+// Metric seeds an active metric name along with the tags it is reported with.
+type Metric struct {
+	Name     string
+	Tags     []string
+	Metadata datadog.MetricMetadata
+	// ActiveTags lists the tag keys actively queried.
+	ActiveTags []string
+}
+
+// Backend is an in-memory datadog.Backend. Its fields may be replaced or
+// extended before use; it is safe for concurrent use once populated.
+type Backend struct {
+	Series     []Series
+	Metrics    []Metric
+	Spans      []datadog.Span
+	Services   []datadog.ServiceInfo
+	Dashboards []datadog.Dashboard
+
+	// Formulas seeds the response to timeseries formulas, matched by the
+	// formula text in Query.
+	Formulas []Series
+
+	// Errors makes the named method (e.g. "QuerySpans") fail with the given error.
+	Errors map[string]error
+
+	mu    sync.Mutex
+	calls []string
+}
+
+var _ datadog.Backend = (*Backend)(nil)
+
+// New returns a Backend seeded with a small, consistent data set centred on
+// a "checkout" service.
+func New() *Backend {
+	spanStart := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	return &Backend{
+		Series: []Series{
+			{Query: "avg:system.cpu.user{*}", Metric: "system.cpu.user", Tags: []string{"*"}, Unit: "percent", Values: []float64{12.5, 14, 13.2, 40.1, 15.3}},
+			{Query: "avg:system.cpu.user{*} by {host}", Metric: "system.cpu.user", Tags: []string{"host:web-1"}, Unit: "percent", Values: []float64{10, 11, 12, 13}},
+			{Query: "avg:system.cpu.user{*} by {host}", Metric: "system.cpu.user", Tags: []string{"host:web-2"}, Unit: "percent", Values: []float64{20, 21, 22, 60}},
+			{Query: "avg:system.mem.used{*}", Metric: "system.mem.used", Tags: []string{"*"}, Values: []float64{3.1e9, 3.2e9, 3.4e9}},
+			{Query: "avg:trace.checkout.duration{service:checkout}", Metric: "trace.checkout.duration", Values: []float64{120e6, 130e6, 110e6}},
+			{Query: "p95:trace.checkout.duration{service:checkout}", Metric: "trace.checkout.duration", Values: []float64{300e6, 320e6, 310e6}},
+			{Query: "sum:trace.checkout.errors{service:checkout}.as_count()", Metric: "trace.checkout.errors", Values: []float64{2, 0, 3}},
+			{Query: "sum:trace.checkout.hits{service:checkout}.as_count()", Metric: "trace.checkout.hits", Values: []float64{400, 350, 450}},
+		},
+		Formulas: []Series{
+			{Query: "a / b * 100", Values: []float64{2.0 / 400 * 100, 0, 3.0 / 450 * 100}},
+		},
+		Metrics: []Metric{
+			{Name: "system.cpu.user", Tags: []string{"host:web-1", "host:web-2", "env:production", "availability-zone:us-east-1a", "availability-zone:us-east-1b"}, ActiveTags: []string{"host"},
+				Metadata: datadog.MetricMetadata{Type: "gauge", Unit: "percent", Description: "The percent of time the CPU spent running user space processes.", Integration: "system", StatsdInterval: 15}},
+			{Name: "system.mem.used", Tags: []string{"host:web-1", "host:web-2", "env:production"},
+				Metadata: datadog.MetricMetadata{Type: "gauge", Unit: "byte", Description: "The amount of RAM in use.", Integration: "system", StatsdInterval: 15}},
+			{Name: "trace.checkout.duration", Tags: []string{"service:checkout", "env:production"},
+				Metadata: datadog.MetricMetadata{Type: "gauge", Unit: "nanosecond", Description: "Total time spent processing requests."}},
+			{Name: "trace.checkout.errors", Tags: []string{"service:checkout", "env:production"},
+				Metadata: datadog.MetricMetadata{Type: "count", Unit: "error", Description: "Count of requests that errored."}},
+			{Name: "trace.checkout.hits", Tags: []string{"service:checkout", "env:production"},
+				Metadata: datadog.MetricMetadata{Type: "count", Unit: "hit", Description: "Count of requests received."}},
+		},
+		Spans: []datadog.Span{
+			{TraceID: "1001", SpanID: "2001", Service: "checkout", Name: "POST /cart/checkout", Resource: "POST /cart/checkout", Type: "web", Start: spanStart, Duration: int64(120 * time.Millisecond), Status: "ok"},
+			{TraceID: "1002", SpanID: "2002", Service: "checkout", Name: "POST /cart/checkout", Resource: "POST /cart/checkout", Type: "web", Start: spanStart.Add(time.Second), Duration: int64(950 * time.Millisecond), Status: "error", Error: 1},
+			{TraceID: "1002", SpanID: "2003", ParentID: "2002", Service: "payments", Name: "charge", Resource: "charge", Type: "http", Start: spanStart.Add(time.Second), Duration: int64(900 * time.Millisecond), Status: "error", Error: 1},
+		},
+		Services: []datadog.ServiceInfo{
+			{Name: "checkout", Description: "Cart checkout API", Team: "shop", Tier: "1", Lifecycle: "production", Languages: []string{"go"}},
+			{Name: "payments", Description: "Payment processing", Team: "billing", Tier: "1", Lifecycle: "production", Languages: []string{"java"},
+				Contacts: []datadog.ServiceContact{{Type: "slack", Contact: "https://example.slack.com/archives/payments"}}},
+		},
+		Dashboards: []datadog.Dashboard{
+			{ID: "abc-123-def", Title: "Checkout Overview", Description: "Golden signals for checkout", LayoutType: "ordered", AuthorHandle: "shop@example.com",
+				Widgets:           []datadog.DashboardWidget{{ID: 1, Definition: map[string]interface{}{"type": "timeseries"}}},
+				TemplateVariables: []datadog.DashboardTemplateVariable{{Name: "env", Prefix: "env", Default: "production"}}},
+			{ID: "ghi-456-jkl", Title: "Host Health", LayoutType: "free"},
+		},
+	}
+}
+
+// Calls returns the names of the methods invoked so far, in order.
+func (b *Backend) Calls() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.calls)
+}
+
+// record notes a call to method and returns its seeded error, if any.
+func (b *Backend) record(ctx context.Context, method string) error {
+	b.mu.Lock()
+	b.calls = append(b.calls, method)
+	b.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.Errors[method]
+}
+
+// QueryMetrics returns the seeded series whose Query matches query exactly.
+func (b *Backend) QueryMetrics(ctx context.Context, query string, from, to time.Time) (*datadog.QueryMetricsResult, error) {
+	if err := b.record(ctx, "QueryMetrics"); err != nil {
+		return nil, fmt.Errorf("failed to query metrics: %w", err)
+	}
+
+	result := &datadog.QueryMetricsResult{
+		Series: make([]datadog.MetricSeries, 0),
+		Query:  query,
+		From:   from,
+		To:     to,
+	}
+
+	for _, s := range b.Series {
+		if s.Query != query {
+			continue
+		}
+		result.Series = append(result.Series, spread(s.Metric, s.Tags, s.Unit, s.Values, from, to))
+	}
+
+	return result, nil
+}
+
+// ListMetrics returns the seeded metric names reported with the given host
+// and tag filter.
+func (b *Backend) ListMetrics(ctx context.Context, from time.Time, host string, tagFilter string) (*datadog.ListMetricsResult, error) {
+	if err := b.record(ctx, "ListMetrics"); err != nil {
+		return nil, fmt.Errorf("failed to list metrics: %w", err)
+	}
+
+	result := &datadog.ListMetricsResult{
+		Metrics: make([]string, 0),
+		From:    from.Unix(),
+	}
+
+	for _, m := range b.Metrics {
+		if host != "" && !slices.Contains(m.Tags, "host:"+host) {
+			continue
+		}
+		if tagFilter != "" && !slices.Contains(m.Tags, tagFilter) {
+			continue
+		}
+		result.Metrics = append(result.Metrics, m.Name)
+	}
+
+	return result, nil
+}
+
+// QuerySpans returns the seeded spans matching every key:value term in query.
+// Supported keys are service, resource_name, status and span tags. The time
+// range is not applied, so the seeded spans are found at any time. The cursor
+// is the offset of the next page.
+func (b *Backend) QuerySpans(ctx context.Context, query string, from, to time.Time, limit int32, cursor string) (*datadog.QuerySpansResult, error) {
+	if err := b.record(ctx, "QuerySpans"); err != nil {
+		return nil, fmt.Errorf("failed to query spans: %w", err)
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	matched := make([]datadog.Span, 0)
+	for _, span := range b.Spans {
+		if spanMatches(span, query) {
+			matched = append(matched, span)
+		}
+	}
+
+	offset := 0
+	if cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 {
+			return nil, datadog.NewAPIError("failed to query spans", http.StatusBadRequest, fmt.Sprintf("invalid cursor %q", cursor))
+		}
+		offset = min(n, len(matched))
+	}
+	end := min(offset+int(limit), len(matched))
+
+	result := &datadog.QuerySpansResult{
+		Spans: matched[offset:end],
+		From:  from,
+		To:    to,
+	}
+	result.TotalCount = len(result.Spans)
+	if end < len(matched) {
+		result.NextCursor = strconv.Itoa(end)
+	}
+
+	return result, nil
+}
+
+// spanMatches reports whether span satisfies every term of query.
+func spanMatches(span datadog.Span, query string) bool {
+	for _, term := range strings.Fields(query) {
+		if term == "*" {
+			continue
+		}
+		key, value, ok := strings.Cut(term, ":")
+		if !ok {
+			return false
+		}
+		var actual string
+		switch key {
+		case "service":
+			actual = span.Service
+		case "resource_name":
+			actual = span.Resource
+		case "status":
+			actual = span.Status
+		default:
+			actual = span.Tags[strings.TrimPrefix(key, "@")]
+		}
+		if actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ListServices returns the seeded services.
+func (b *Backend) ListServices(ctx context.Context) (*datadog.ListServicesResult, error) {
+	if err := b.record(ctx, "ListServices"); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+
+	return &datadog.ListServicesResult{
+		Services: slices.Clone(b.Services),
+		Total:    len(b.Services),
+	}, nil
+}
+
+// ListDashboards returns summaries of the seeded dashboards, paginated by
+// start and limit.
+func (b *Backend) ListDashboards(ctx context.Context, filterShared, filterDeleted bool, limit, start int64) (*datadog.ListDashboardsResult, error) {
+	if err := b.record(ctx, "ListDashboards"); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	result := &datadog.ListDashboardsResult{
+		Dashboards: make([]datadog.DashboardSummary, 0),
+		Start:      start,
+	}
+
+	first := min(start, int64(len(b.Dashboards)))
+	last := min(first+limit, int64(len(b.Dashboards)))
+	for _, d := range b.Dashboards[first:last] {
+		result.Dashboards = append(result.Dashboards, datadog.DashboardSummary{
+			ID:           d.ID,
+			Title:        d.Title,
+			Description:  d.Description,
+			LayoutType:   d.LayoutType,
+			URL:          d.URL,
+			AuthorHandle: d.AuthorHandle,
+			CreatedAt:    d.CreatedAt,
+			ModifiedAt:   d.ModifiedAt,
+			IsReadOnly:   d.IsReadOnly,
+		})
+	}
+	result.Total = int64(len(result.Dashboards))
+	result.HasMore = last < int64(len(b.Dashboards))
+
+	return result, nil
+}
+
+// GetMetricMetadata returns the metadata of the seeded metric with the given
+// name.
+func (b *Backend) GetMetricMetadata(ctx context.Context, metric string) (*datadog.MetricMetadata, error) {
+	if err := b.record(ctx, "GetMetricMetadata"); err != nil {
+		return nil, fmt.Errorf("failed to get metric metadata: %w", err)
+	}
+
+	for _, m := range b.Metrics {
+		if m.Name == metric {
+			md := m.Metadata
+			md.Metric = metric
+			return &md, nil
+		}
+	}
+	return nil, datadog.NewAPIError("failed to get metric metadata", http.StatusNotFound, fmt.Sprintf("Metric %s not found", metric))
+}
+
+// ListMetricTags returns the tags of the seeded metric with the given name.
+func (b *Backend) ListMetricTags(ctx context.Context, metric string) (*datadog.MetricTags, error) {
+	if err := b.record(ctx, "ListMetricTags"); err != nil {
+		return nil, fmt.Errorf("failed to list metric tags: %w", err)
+	}
+
+	for _, m := range b.Metrics {
+		if m.Name == metric {
+			return &datadog.MetricTags{
+				Metric:     metric,
+				Tags:       slices.Clone(m.Tags),
+				ActiveTags: slices.Clone(m.ActiveTags),
+			}, nil
+		}
+	}
+	return nil, datadog.NewAPIError("failed to list metric tags", http.StatusNotFound, fmt.Sprintf("Metric %s not found", metric))
+}
+
+// GetDashboard returns the seeded dashboard with the given ID.
+func (b *Backend) GetDashboard(ctx context.Context, dashboardID string) (*datadog.Dashboard, error) {
+	if err := b.record(ctx, "GetDashboard"); err != nil {
+		return nil, err
+	}
+
+	for _, d := range b.Dashboards {
+		if d.ID == dashboardID {
+			d.WidgetCount = len(d.Widgets)
+			return &d, nil
+		}
+	}
+	return nil, datadog.NewAPIError("failed to get dashboard", http.StatusNotFound, fmt.Sprintf("Dashboard %s not found", dashboardID))
+}
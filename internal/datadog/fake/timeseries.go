@@ -0,0 +1,93 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// QueryTimeseries answers metric queries with the seeded series whose Query
+// matches the query exactly, and event queries with no series. Formulas are
+// not evaluated: each is answered with the seeded Formulas whose Query matches
+// it exactly, once every query name it refers to is known. The interval is
+// ignored: values are spread over the range as in QueryMetrics.
+func (b *Backend) QueryTimeseries(ctx context.Context, queries []datadog.TimeseriesQuery, formulas []string, from, to time.Time, interval time.Duration) (*datadog.QueryTimeseriesResult, error) {
+	if err := b.record(ctx, "QueryTimeseries"); err != nil {
+		return nil, fmt.Errorf("failed to query timeseries: %w", err)
+	}
+
+	result := &datadog.QueryTimeseriesResult{
+		Series:   make([]datadog.MetricSeries, 0),
+		Queries:  queries,
+		Formulas: formulas,
+		From:     from,
+		To:       to,
+	}
+
+	names := make([]string, 0, len(queries))
+	for _, q := range queries {
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+		names = append(names, q.Name)
+		if len(formulas) > 0 || q.Events() {
+			continue
+		}
+		for _, s := range b.Series {
+			if s.Query == q.MetricQuery() {
+				result.Series = append(result.Series, spread(q.Name, s.Tags, s.Unit, s.Values, from, to))
+			}
+		}
+	}
+
+	for _, f := range formulas {
+		for _, name := range formulaName.FindAllString(f, -1) {
+			if !slices.Contains(names, name) {
+				return nil, datadog.NewAPIError("failed to query timeseries", http.StatusBadRequest, fmt.Sprintf("invalid formula %q: unknown query %q", f, name))
+			}
+		}
+		for _, s := range b.Formulas {
+			if s.Query == f {
+				result.Series = append(result.Series, spread(f, s.Tags, s.Unit, s.Values, from, to))
+			}
+		}
+	}
+
+	for _, ms := range result.Series {
+		if len(ms.DataPoints) > 1 {
+			result.Interval = int64(ms.DataPoints[1].Timestamp.Sub(ms.DataPoints[0].Timestamp) / time.Second)
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// formulaName matches the query names a formula refers to.
+var formulaName = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// spread returns a series of values spread evenly across from to to.
+func spread(metric string, tags []string, unit string, values []float64, from, to time.Time) datadog.MetricSeries {
+	ms := datadog.MetricSeries{
+		Metric:     metric,
+		Tags:       slices.Clone(tags),
+		Unit:       unit,
+		DataPoints: make([]datadog.MetricPoint, 0, len(values)),
+	}
+	step := to.Sub(from)
+	if len(values) > 1 {
+		step /= time.Duration(len(values) - 1)
+	}
+	for i, v := range values {
+		ms.DataPoints = append(ms.DataPoints, datadog.MetricPoint{
+			Timestamp: from.Add(time.Duration(i) * step),
+			Value:     v,
+		})
+	}
+	return ms
+}
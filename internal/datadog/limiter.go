@@ -0,0 +1,217 @@
+package datadog
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+type sessionKey struct{}
+
+// WithSession returns a context whose Datadog API requests are attributed to
+// session, so that queued requests are scheduled fairly between sessions.
+func WithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+func sessionFrom(ctx context.Context) string {
+	session, _ := ctx.Value(sessionKey{}).(string)
+	return session
+}
+
+// limitTransport bounds the number of concurrent requests, both per endpoint
+// family and overall. Requests beyond the limits wait in a queue; when a slot
+// frees up it goes to the next session in turn, so one busy session cannot
+// starve the others.
+type limitTransport struct {
+	next     http.RoundTripper
+	org      string
+	global   *fairSemaphore
+	families map[string]*fairSemaphore
+}
+
+// newLimitTransport returns a transport allowing at most global concurrent
+// requests, and at most families[f] concurrent requests to family f. Zero
+// means unlimited. Queueing is recorded in the metrics of org.
+func newLimitTransport(next http.RoundTripper, org string, global int, families map[string]int) http.RoundTripper {
+	t := &limitTransport{
+		next:     next,
+		org:      org,
+		families: make(map[string]*fairSemaphore),
+	}
+	if global > 0 {
+		t.global = newFairSemaphore(global)
+	}
+	for family, limit := range families {
+		if limit > 0 {
+			t.families[family] = newFairSemaphore(limit)
+		}
+	}
+	if t.global == nil && len(t.families) == 0 {
+		return next
+	}
+	return t
+}
+
+func (t *limitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	session := sessionFrom(ctx)
+	family := Family(req.URL.Path)
+
+	start := time.Now()
+	waited := false
+
+	// Take the family slot before the global one, so that a request queued
+	// behind a busy family does not hold a global slot other families could use.
+	for _, sem := range []*fairSemaphore{t.families[family], t.global} {
+		if sem == nil {
+			continue
+		}
+		queued, err := sem.acquire(ctx, session)
+		if err != nil {
+			t.release(family, sem)
+			return nil, err
+		}
+		waited = waited || queued
+	}
+
+	wait := time.Since(start)
+	telemetry.UpstreamQueueDuration.WithLabelValues(t.org, family).Observe(wait.Seconds())
+	if trace, _ := ctx.Value(traceKey{}).(*Trace); trace != nil && waited {
+		trace.addQueued(wait)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		t.release(family, nil)
+		return resp, err
+	}
+	// Hold the slots while the body downloads, until the caller closes it.
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: func() { t.release(family, nil) }}
+	return resp, nil
+}
+
+// releaseBody is a response body that frees the limiter slots of its request
+// when closed.
+type releaseBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// release frees the slots held for a request to family. If failed is not nil,
+// acquiring failed slot failed, and only the slots taken before it are freed.
+func (t *limitTransport) release(family string, failed *fairSemaphore) {
+	for _, sem := range []*fairSemaphore{t.families[family], t.global} {
+		if sem == nil {
+			continue
+		}
+		if sem == failed {
+			return
+		}
+		sem.release()
+	}
+}
+
+// fairSemaphore is a counting semaphore whose waiters are served round-robin
+// by session, and in FIFO order within a session.
+type fairSemaphore struct {
+	mu     sync.Mutex
+	limit  int
+	inUse  int
+	queues map[string][]*semWaiter
+	// turns lists the sessions with queued waiters, next to be served first.
+	turns []string
+}
+
+type semWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newFairSemaphore(limit int) *fairSemaphore {
+	return &fairSemaphore{
+		limit:  limit,
+		queues: make(map[string][]*semWaiter),
+	}
+}
+
+// acquire takes a slot, waiting for one to free up if necessary. It reports
+// whether the caller had to wait.
+func (s *fairSemaphore) acquire(ctx context.Context, session string) (bool, error) {
+	s.mu.Lock()
+	if s.inUse < s.limit && len(s.turns) == 0 {
+		s.inUse++
+		s.mu.Unlock()
+		return false, nil
+	}
+
+	w := &semWaiter{ready: make(chan struct{})}
+	if len(s.queues[session]) == 0 {
+		s.turns = append(s.turns, session)
+	}
+	s.queues[session] = append(s.queues[session], w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return true, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	if w.granted {
+		// The slot was handed over while we gave up; pass it on.
+		s.mu.Unlock()
+		s.release()
+		return true, ctx.Err()
+	}
+	queue := s.queues[session]
+	i := slices.Index(queue, w)
+	queue = slices.Delete(queue, i, i+1)
+	if len(queue) == 0 {
+		delete(s.queues, session)
+		s.turns = slices.DeleteFunc(s.turns, func(t string) bool { return t == session })
+	} else {
+		s.queues[session] = queue
+	}
+	s.mu.Unlock()
+	return true, ctx.Err()
+}
+
+// release frees a slot, handing it to the first waiter of the next session in
+// turn, if any.
+func (s *fairSemaphore) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.turns) == 0 {
+		s.inUse--
+		return
+	}
+
+	session := s.turns[0]
+	queue := s.queues[session]
+	w := queue[0]
+	s.turns = s.turns[1:]
+	if len(queue) > 1 {
+		s.queues[session] = queue[1:]
+		s.turns = append(s.turns, session)
+	} else {
+		delete(s.queues, session)
+	}
+
+	w.granted = true
+	close(w.ready)
+}
@@ -0,0 +1,130 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestLimitTransportHoldsSlotUntilBodyClosed(t *testing.T) {
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	})
+	transport := newLimitTransport(next, "test", 1, nil)
+
+	newRequest := func(ctx context.Context) *http.Request {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.datadoghq.com/api/v1/query", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+
+	first, err := transport.RoundTrip(newRequest(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := transport.RoundTrip(newRequest(ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second request while the first body is open: err = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if _, err := io.ReadAll(first.Body); err != nil {
+		t.Fatal(err)
+	}
+	first.Body.Close()
+	first.Body.Close()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	second, err := transport.RoundTrip(newRequest(ctx))
+	if err != nil {
+		t.Fatalf("request after the first body was closed: %v", err)
+	}
+	second.Body.Close()
+}
+
+func TestLimitTransportReleasesOnError(t *testing.T) {
+	failure := errors.New("connection refused")
+	next := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, failure
+	})
+	transport := newLimitTransport(next, "test", 1, nil)
+
+	for range 2 {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.datadoghq.com/api/v1/query", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := transport.RoundTrip(req); !errors.Is(err, failure) {
+			t.Fatalf("err = %v, want %v", err, failure)
+		}
+		cancel()
+	}
+}
+
+func TestFairSemaphoreRoundRobin(t *testing.T) {
+	s := newFairSemaphore(1)
+	ctx := context.Background()
+	if _, err := s.acquire(ctx, "flood"); err != nil {
+		t.Fatal(err)
+	}
+
+	// queued reports how many waiters the semaphore holds.
+	queued := func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		n := 0
+		for _, q := range s.queues {
+			n += len(q)
+		}
+		return n
+	}
+
+	granted := make(chan string)
+	enqueue := func(session, name string) {
+		want := queued() + 1
+		go func() {
+			if _, err := s.acquire(ctx, session); err != nil {
+				t.Error(err)
+			}
+			granted <- name
+		}()
+		for queued() < want {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	for _, name := range []string{"flood-1", "flood-2", "flood-3"} {
+		enqueue("flood", name)
+	}
+	enqueue("other", "other-1")
+
+	var order []string
+	for range 4 {
+		s.release()
+		order = append(order, <-granted)
+	}
+	s.release()
+
+	want := []string{"flood-1", "other-1", "flood-2", "flood-3"}
+	if !slices.Equal(order, want) {
+		t.Errorf("slots granted in order %v, want %v", order, want)
+	}
+	if s.inUse != 0 {
+		t.Errorf("%d slots in use after every waiter released, want 0", s.inUse)
+	}
+}
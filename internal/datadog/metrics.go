@@ -2,7 +2,7 @@ package datadog
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV1"
@@ -16,10 +16,38 @@ type MetricPoint struct {
 
 // MetricSeries represents a metric timeseries with its data points.
 type MetricSeries struct {
-	Metric     string        `json:"metric"`
-	Tags       []string      `json:"tags,omitempty"`
-	Unit       string        `json:"unit,omitempty"`
+	Metric string   `json:"metric"`
+	Tags   []string `json:"tags,omitempty"`
+	Unit   string   `json:"unit,omitempty"`
+	// PerUnit is the unit of a rate, e.g. second for bytes per second.
+	PerUnit string `json:"per_unit,omitempty"`
+	// Type is the metric type from its metadata, e.g. gauge or count.
+	Type       string        `json:"type,omitempty"`
 	DataPoints []MetricPoint `json:"data_points"`
+	// OriginalPoints is the number of points Datadog returned, when the
+	// series was downsampled to fewer.
+	OriginalPoints int `json:"original_points,omitempty"`
+	// Resolution is the average time between the points in seconds.
+	Resolution float64 `json:"resolution,omitempty"`
+	// Stats summarizes the values of the series before downsampling.
+	Stats *SeriesStats `json:"stats,omitempty"`
+}
+
+// SeriesStats summarizes the values of a metric series.
+type SeriesStats struct {
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Mean float64 `json:"mean"`
+	Last float64 `json:"last"`
+	P50  float64 `json:"p50"`
+	P95  float64 `json:"p95"`
+	// Slope is the change per hour of the least-squares line through the
+	// points.
+	Slope float64 `json:"slope"`
+	// Trend is rising, falling or flat, from the change of the fitted line
+	// over the whole series.
+	Trend  string    `json:"trend"`
+	PeakAt time.Time `json:"peak_at"`
 }
 
 // QueryMetricsResult contains the result of a metrics query.
@@ -30,13 +58,19 @@ type QueryMetricsResult struct {
 	To          time.Time      `json:"to"`
 	TotalSeries int            `json:"total_series,omitempty"`
 	Truncated   bool           `json:"truncated,omitempty"`
+	// Downsample is the strategy that fit series into the data point limit,
+	// when any series had more points.
+	Downsample string `json:"downsample,omitempty"`
+	// Warnings holds what left the series incomplete, such as metric
+	// metadata that could not be looked up.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // QueryMetrics queries timeseries metrics from Datadog.
 func (c *Client) QueryMetrics(ctx context.Context, query string, from, to time.Time) (*QueryMetricsResult, error) {
-	resp, _, err := c.metricsV1.QueryMetrics(c.ctx, from.Unix(), to.Unix(), query)
+	resp, httpResp, err := c.metricsV1.QueryMetrics(c.Context(ctx), from.Unix(), to.Unix(), query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query metrics: %w", err)
+		return nil, apiError("failed to query metrics", httpResp, err)
 	}
 
 	result := &QueryMetricsResult{
@@ -53,8 +87,12 @@ func (c *Client) QueryMetrics(ctx context.Context, query string, from, to time.T
 			DataPoints: make([]MetricPoint, 0),
 		}
 
-		if unit := series.GetUnit(); len(unit) > 0 && unit[0].GetName() != "" {
+		// Datadog describes the unit and, for rates, the per unit.
+		if unit := series.GetUnit(); len(unit) > 0 {
 			ms.Unit = unit[0].GetName()
+			if len(unit) > 1 {
+				ms.PerUnit = unit[1].GetName()
+			}
 		}
 
 		if pointList := series.GetPointlist(); pointList != nil {
@@ -84,7 +122,20 @@ type ListMetricsResult struct {
 }
 
 // ListMetrics lists active metrics from Datadog.
+//
+// The set of metrics active over a long window barely moves, so from is
+// rounded down to the cache TTL to let nearby calls share a cached response.
 func (c *Client) ListMetrics(ctx context.Context, from time.Time, host string, tagFilter string) (*ListMetricsResult, error) {
+	if ttl := c.cache.ttl("ListMetrics"); ttl > 0 {
+		from = from.Truncate(ttl)
+	}
+	key := cacheKey("ListMetrics", strconv.FormatInt(from.Unix(), 10), host, tagFilter)
+	return cached(ctx, c.cache, "ListMetrics", key, func(ctx context.Context) (*ListMetricsResult, error) {
+		return c.listMetrics(ctx, from, host, tagFilter)
+	})
+}
+
+func (c *Client) listMetrics(ctx context.Context, from time.Time, host string, tagFilter string) (*ListMetricsResult, error) {
 	opts := datadogV1.NewListActiveMetricsOptionalParameters()
 	if host != "" {
 		opts = opts.WithHost(host)
@@ -93,9 +144,9 @@ func (c *Client) ListMetrics(ctx context.Context, from time.Time, host string, t
 		opts = opts.WithTagFilter(tagFilter)
 	}
 
-	resp, _, err := c.metricsV1.ListActiveMetrics(c.ctx, from.Unix(), *opts)
+	resp, httpResp, err := c.metricsV1.ListActiveMetrics(c.Context(ctx), from.Unix(), *opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list metrics: %w", err)
+		return nil, apiError("failed to list metrics", httpResp, err)
 	}
 
 	result := &ListMetricsResult{
@@ -107,3 +158,73 @@ func (c *Client) ListMetrics(ctx context.Context, from time.Time, host string, t
 
 	return result, nil
 }
+
+// MetricMetadata describes what a metric measures.
+type MetricMetadata struct {
+	Metric         string `json:"metric"`
+	Type           string `json:"type,omitempty"`
+	Unit           string `json:"unit,omitempty"`
+	PerUnit        string `json:"per_unit,omitempty"`
+	Description    string `json:"description,omitempty"`
+	ShortName      string `json:"short_name,omitempty"`
+	Integration    string `json:"integration,omitempty"`
+	StatsdInterval int64  `json:"statsd_interval,omitempty"`
+}
+
+// GetMetricMetadata returns the metadata of the named metric: its type, unit
+// and description.
+func (c *Client) GetMetricMetadata(ctx context.Context, metric string) (*MetricMetadata, error) {
+	return cached(ctx, c.cache, "GetMetricMetadata", cacheKey("GetMetricMetadata", metric), func(ctx context.Context) (*MetricMetadata, error) {
+		resp, httpResp, err := c.metricsV1.GetMetricMetadata(c.Context(ctx), metric)
+		if err != nil {
+			return nil, apiError("failed to get metric metadata", httpResp, err)
+		}
+		return &MetricMetadata{
+			Metric:         metric,
+			Type:           resp.GetType(),
+			Unit:           resp.GetUnit(),
+			PerUnit:        resp.GetPerUnit(),
+			Description:    resp.GetDescription(),
+			ShortName:      resp.GetShortName(),
+			Integration:    resp.GetIntegration(),
+			StatsdInterval: resp.GetStatsdInterval(),
+		}, nil
+	})
+}
+
+// MetricTags lists the tags a metric is reported with.
+type MetricTags struct {
+	Metric string `json:"metric"`
+	// Tags holds the indexed key:value tags of the metric over the past
+	// hour.
+	Tags []string `json:"tags"`
+	// ActiveTags holds the tag keys actively queried on dashboards,
+	// notebooks, monitors and through the API.
+	ActiveTags []string `json:"active_tags,omitempty"`
+}
+
+// ListMetricTags returns the tags the named metric was reported with over
+// the past hour, and the tag keys actively queried.
+func (c *Client) ListMetricTags(ctx context.Context, metric string) (*MetricTags, error) {
+	return cached(ctx, c.cache, "ListMetricTags", cacheKey("ListMetricTags", metric), func(ctx context.Context) (*MetricTags, error) {
+		resp, httpResp, err := c.metricsV2.ListTagsByMetricName(c.Context(ctx), metric)
+		if err != nil {
+			return nil, apiError("failed to list metric tags", httpResp, err)
+		}
+		result := &MetricTags{
+			Metric: metric,
+			Tags:   resp.GetData().Attributes.GetTags(),
+		}
+		if result.Tags == nil {
+			result.Tags = make([]string, 0)
+		}
+
+		// Active tags only rank the keys, so metrics without any, or API keys
+		// without access to them, still get their tags listed.
+		active, _, err := c.metricsV2.ListActiveMetricConfigurations(c.Context(ctx), metric)
+		if err == nil {
+			result.ActiveTags = active.GetData().Attributes.GetActiveTags()
+		}
+		return result, nil
+	})
+}
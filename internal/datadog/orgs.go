@@ -0,0 +1,50 @@
+package datadog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Orgs resolves the Backend of each configured Datadog organization.
+type Orgs struct {
+	backends   map[string]Backend
+	defaultOrg string
+}
+
+// NewOrgs returns an Orgs serving backends, keyed by organization name, with
+// defaultOrg used when a caller does not name an organization.
+func NewOrgs(defaultOrg string, backends map[string]Backend) *Orgs {
+	return &Orgs{
+		backends:   backends,
+		defaultOrg: defaultOrg,
+	}
+}
+
+// Get returns the Backend of the named organization, or of the default
+// organization if name is empty.
+func (o *Orgs) Get(name string) (Backend, error) {
+	if name == "" {
+		name = o.defaultOrg
+	}
+	backend, ok := o.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown org %q; available orgs: %s", name, strings.Join(o.Names(), ", "))
+	}
+	return backend, nil
+}
+
+// Default returns the name of the default organization.
+func (o *Orgs) Default() string {
+	return o.defaultOrg
+}
+
+// Names returns the names of all organizations in sorted order.
+func (o *Orgs) Names() []string {
+	names := make([]string, 0, len(o.backends))
+	for name := range o.backends {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package datadog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit holds the rate limit state Datadog reports in the
+// X-RateLimit-* headers of an API response.
+type RateLimit struct {
+	Name      string        `json:"name,omitempty"`
+	Limit     int           `json:"limit"`
+	Remaining int           `json:"remaining"`
+	Period    time.Duration `json:"period"`
+	Reset     time.Duration `json:"reset"`
+}
+
+// String formats the rate limit for inclusion in error messages.
+func (rl RateLimit) String() string {
+	s := fmt.Sprintf("%d/%d requests remaining, resets in %s", rl.Remaining, rl.Limit, rl.Reset)
+	if rl.Name != "" {
+		s = rl.Name + ": " + s
+	}
+	return s
+}
+
+// parseRateLimit extracts the rate limit headers from resp. It reports false
+// when resp is nil or carries no rate limit information.
+func parseRateLimit(resp *http.Response) (RateLimit, bool) {
+	if resp == nil {
+		return RateLimit{}, false
+	}
+
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	rl := RateLimit{
+		Name:  resp.Header.Get("X-RateLimit-Name"),
+		Limit: limit,
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		rl.Remaining = v
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Period")); err == nil {
+		rl.Period = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Reset")); err == nil {
+		rl.Reset = time.Duration(v) * time.Second
+	}
+
+	return rl, true
+}
@@ -0,0 +1,360 @@
+package datadog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const scrubbed = "[SCRUBBED]"
+
+// ErrNotRecorded is returned in replay mode for requests that match no
+// recorded exchange.
+var ErrNotRecorded = errors.New("no recorded response")
+
+// secretHeaders lists the request headers that carry credentials.
+var secretHeaders = []string{"Dd-Api-Key", "Dd-Application-Key", "Authorization", "Cookie"}
+
+// secretParams lists the query parameters that carry credentials.
+var secretParams = []string{"api_key", "application_key"}
+
+// timeFields lists the query parameters and JSON body fields holding the
+// time range of a request. They change on every run when callers use
+// relative times, so replay ignores them when matching requests.
+var timeFields = []string{"from", "to", "start", "end", "filter[from]", "filter[to]"}
+
+// Exchange is one recorded request and the response Datadog gave to it.
+type Exchange struct {
+	Key string `json:"key"`
+	// Index is the position of the exchange among those recorded with the
+	// same key.
+	Index    int              `json:"index"`
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request with its credentials scrubbed.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   body        `json:"body,omitempty"`
+}
+
+// RecordedResponse is a response as received from Datadog.
+type RecordedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   body        `json:"body,omitempty"`
+}
+
+// body stores JSON payloads as-is so fixtures stay readable, and anything
+// else as a JSON string.
+type body []byte
+
+func (b body) MarshalJSON() ([]byte, error) {
+	if len(b) == 0 {
+		return []byte("null"), nil
+	}
+	if json.Valid(b) {
+		return b, nil
+	}
+	return json.Marshal(string(b))
+}
+
+func (b *body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*b = body(s)
+		return nil
+	}
+	if string(data) == "null" {
+		*b = nil
+		return nil
+	}
+	*b = slices.Clone(data)
+	return nil
+}
+
+// recordTransport writes every exchange passing through it to a directory,
+// one JSON file per exchange, with credentials scrubbed.
+type recordTransport struct {
+	next http.RoundTripper
+	dir  string
+
+	mu sync.Mutex
+	// recorded counts the exchanges saved per key.
+	recorded map[string]int
+}
+
+// NewRecorder returns a transport sending requests through next and saving
+// each exchange in dir, creating it if needed. Exchanges already in dir are
+// kept, and new ones indexed after those with the same key.
+func NewRecorder(dir string, next http.RoundTripper) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	existing, err := loadExchanges(dir)
+	if err != nil {
+		return nil, err
+	}
+	t := &recordTransport{next: next, dir: dir, recorded: make(map[string]int)}
+	for _, exchange := range existing {
+		t.recorded[exchange.Key]++
+	}
+	return t, nil
+}
+
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := Exchange{
+		Key: exchangeKey(req.Method, req.URL, reqBody),
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    scrubURL(req.URL),
+			Header: scrubHeader(req.Header),
+			Body:   reqBody,
+		},
+		Response: RecordedResponse{
+			Status: resp.StatusCode,
+			Header: resp.Header.Clone(),
+			Body:   respBody,
+		},
+	}
+	exchange.Response.Header.Del("Set-Cookie")
+
+	if err := t.save(exchange); err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to record exchange: %w", err)
+	}
+	return resp, nil
+}
+
+func (t *recordTransport) save(exchange Exchange) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	exchange.Index = t.recorded[exchange.Key]
+	b, err := json.MarshalIndent(exchange, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, exchangeFile(exchange.Key, exchange.Index)), b, 0o600); err != nil {
+		return err
+	}
+	t.recorded[exchange.Key]++
+	return nil
+}
+
+// exchangeFile names the file of the exchange recorded with key at index,
+// after the request's method and path and a hash of key.
+func exchangeFile(key string, index int) string {
+	method, path, _ := strings.Cut(key, " ")
+	path, _, _ = strings.Cut(path, "?")
+	path, _, _ = strings.Cut(path, " ")
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, strings.Trim(path, "/"))
+	if len(name) > 60 {
+		name = name[:60]
+	}
+	sum := sha256.Sum256([]byte(key))
+	return fmt.Sprintf("%s_%s-%s-%03d.json", method, name, hex.EncodeToString(sum[:4]), index)
+}
+
+// replayTransport answers requests with the exchanges recorded in a
+// directory, without any network access.
+type replayTransport struct {
+	exchanges map[string][]Exchange
+
+	mu sync.Mutex
+	// served counts how many times each key was answered.
+	served map[string]int
+}
+
+// NewReplayer returns a transport answering requests from the exchanges
+// NewRecorder saved in dir. Requests are matched on method, path, query and
+// body, ignoring their time range. The n-th request with a key gets the n-th
+// exchange recorded with that key, whatever other requests were made in
+// between or concurrently; the last one repeats once all were served.
+func NewReplayer(dir string) (http.RoundTripper, error) {
+	exchanges, err := loadExchanges(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges in %s", dir)
+	}
+
+	t := &replayTransport{
+		exchanges: make(map[string][]Exchange),
+		served:    make(map[string]int),
+	}
+	for _, exchange := range exchanges {
+		t.exchanges[exchange.Key] = append(t.exchanges[exchange.Key], exchange)
+	}
+	for _, recorded := range t.exchanges {
+		slices.SortStableFunc(recorded, func(a, b Exchange) int { return a.Index - b.Index })
+	}
+	return t, nil
+}
+
+// loadExchanges reads the exchanges recorded in dir, in file name order.
+func loadExchanges(dir string) ([]Exchange, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(files)
+
+	exchanges := make([]Exchange, 0, len(files))
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var exchange Exchange
+		if err := json.Unmarshal(b, &exchange); err != nil {
+			return nil, fmt.Errorf("invalid recorded exchange %s: %w", file, err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	key := exchangeKey(req.Method, req.URL, reqBody)
+
+	t.mu.Lock()
+	recorded := t.exchanges[key]
+	i := min(t.served[key], len(recorded)-1)
+	t.served[key]++
+	t.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, scrubURL(req.URL))
+	}
+
+	recordedResp := recorded[i].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recordedResp.Status, http.StatusText(recordedResp.Status)),
+		StatusCode:    recordedResp.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recordedResp.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(recordedResp.Body)),
+		ContentLength: int64(len(recordedResp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// readBody reads *rc fully and replaces it with a fresh reader over the same
+// bytes.
+func readBody(rc *io.ReadCloser) ([]byte, error) {
+	if *rc == nil || *rc == http.NoBody {
+		return nil, nil
+	}
+	b, err := io.ReadAll(*rc)
+	(*rc).Close()
+	if err != nil {
+		return nil, err
+	}
+	*rc = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// exchangeKey identifies a request independently of its credentials, host
+// and time range.
+func exchangeKey(method string, u *url.URL, reqBody []byte) string {
+	query := u.Query()
+	for _, name := range slices.Concat(secretParams, timeFields) {
+		query.Del(name)
+	}
+	key := method + " " + u.Path
+	if encoded := query.Encode(); encoded != "" {
+		key += "?" + encoded
+	}
+
+	if len(reqBody) > 0 {
+		var v any
+		if err := json.Unmarshal(reqBody, &v); err == nil {
+			reqBody, _ = json.Marshal(dropTimeFields(v))
+		}
+		sum := sha256.Sum256(reqBody)
+		key += " " + hex.EncodeToString(sum[:6])
+	}
+	return key
+}
+
+// dropTimeFields removes time range fields from a decoded JSON value.
+func dropTimeFields(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for name, value := range v {
+			if slices.Contains(timeFields, name) {
+				delete(v, name)
+			} else {
+				v[name] = dropTimeFields(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = dropTimeFields(value)
+		}
+	}
+	return v
+}
+
+func scrubURL(u *url.URL) string {
+	scrubbedURL := *u
+	query := u.Query()
+	for _, name := range secretParams {
+		if query.Has(name) {
+			query.Set(name, scrubbed)
+		}
+	}
+	scrubbedURL.RawQuery = query.Encode()
+	return scrubbedURL.String()
+}
+
+func scrubHeader(header http.Header) http.Header {
+	h := header.Clone()
+	for name := range h {
+		if slices.ContainsFunc(secretHeaders, func(secret string) bool { return strings.EqualFold(name, secret) }) {
+			h[name] = []string{scrubbed}
+		}
+	}
+	return h
+}
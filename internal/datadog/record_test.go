@@ -0,0 +1,152 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+)
+
+func TestRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	// Record a rate limit answered after a long wait, then two dashboards.
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("DD-API-KEY") != "secret-api-key" {
+			t.Errorf("request without the API key: %v", r.Header)
+		}
+		if requests.Add(1) == 1 {
+			rateLimited(w, 10*time.Second)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/dashboard/abc-123-def":
+			w.Write([]byte(`{"id": "abc-123-def", "title": "Checkout Overview", "layout_type": "ordered", "widgets": []}`))
+		default:
+			w.Write([]byte(`{"id": "xyz-456-uvw", "title": "Payments", "layout_type": "ordered", "widgets": []}`))
+		}
+	}))
+	defer server.Close()
+
+	recorder, err := NewRecorder(dir, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"abc-123-def", "abc-123-def", "xyz-456-uvw"} {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/dashboard/"+id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("DD-API-KEY", "secret-api-key")
+		resp, err := recorder.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("recorded %d files, want 3", len(files))
+	}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(b), "secret-api-key") {
+			t.Errorf("%s contains the API key", filepath.Base(file))
+		}
+		if !strings.HasPrefix(filepath.Base(file), "GET_api_v1_dashboard_") {
+			t.Errorf("file %s is not named after its endpoint", filepath.Base(file))
+		}
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(config.Org{Site: "datadoghq.com"}, WithBaseURL("http://replay.invalid"), WithTransport(replayer), WithCache(0, nil))
+
+	// Requests for the second dashboard do not consume the recordings of
+	// the first, even when made first.
+	dashboard, err := client.GetDashboard(context.Background(), "xyz-456-uvw")
+	if err != nil || dashboard.Title != "Payments" {
+		t.Fatalf("xyz-456-uvw: %+v, %v; want title Payments", dashboard, err)
+	}
+
+	start := time.Now()
+	dashboard, err = client.GetDashboard(context.Background(), "abc-123-def")
+	if err != nil || dashboard.Title != "Checkout Overview" {
+		t.Fatalf("abc-123-def: %+v, %v; want title Checkout Overview", dashboard, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("replaying the rate limit took %s, want no wait", elapsed)
+	}
+
+	_, err = client.GetDashboard(context.Background(), "nope")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("unrecorded request: err = %v, want %v", err, ErrNotRecorded)
+	}
+}
+
+func TestReplayConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"path": "` + r.URL.Path + `"}`))
+	}))
+	defer server.Close()
+
+	recorder, err := NewRecorder(dir, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := []string{"/api/v1/a", "/api/v1/b", "/api/v1/c", "/api/v1/d"}
+	for _, path := range paths {
+		resp, err := recorder.RoundTrip(httptest.NewRequest(http.MethodGet, server.URL+path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for range 4 {
+		for _, path := range paths {
+			wg.Go(func() {
+				req := httptest.NewRequest(http.MethodGet, "http://replay.invalid"+path, nil)
+				req.RequestURI = ""
+				resp, err := replayer.RoundTrip(req)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				defer resp.Body.Close()
+				var answer struct{ Path string }
+				if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil || answer.Path != path {
+					t.Errorf("%s answered with %+v, %v", path, answer, err)
+				}
+			})
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,198 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRetries is the number of times a failed read is retried.
+	maxRetries = 3
+	// retryBaseDelay is the initial backoff between retries.
+	retryBaseDelay = 500 * time.Millisecond
+	// retryMaxDelay caps a single wait. Rate limit resets further out than
+	// this are reported to the caller instead of being waited out.
+	retryMaxDelay = 20 * time.Second
+)
+
+type readRequestKey struct{}
+
+// markRead marks the requests made with ctx as reads that are safe to retry
+// even though they use a non-idempotent HTTP method, such as search
+// endpoints that take their query in a POST body.
+func markRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readRequestKey{}, true)
+}
+
+// retryTransport retries idempotent reads that fail with a rate limit, a
+// transient server error or a network error. It honours Datadog's
+// X-RateLimit-Reset header, and holds back further requests to an endpoint
+// whose quota has been used up until the quota resets.
+type retryTransport struct {
+	next http.RoundTripper
+	// noWait retries at once and ignores used up quotas, for replayed
+	// traffic whose rate limits and failures were recorded earlier.
+	noWait bool
+
+	mu        sync.Mutex
+	exhausted map[string]time.Time
+}
+
+func newRetryTransport(next http.RoundTripper) *retryTransport {
+	return &retryTransport{
+		next:      next,
+		exhausted: make(map[string]time.Time),
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	endpoint := req.Method + " " + req.URL.Path
+
+	if err := t.waitForQuota(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	if !isRetryable(req) {
+		resp, err := t.next.RoundTrip(req)
+		t.observe(endpoint, resp)
+		return resp, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(req)
+		t.observe(endpoint, resp)
+
+		if attempt == maxRetries || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		delay, ok := retryDelay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if t.noWait {
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// waitForQuota blocks until the quota of endpoint has reset, if a previous
+// response reported it as used up.
+func (t *retryTransport) waitForQuota(ctx context.Context, endpoint string) error {
+	if t.noWait {
+		return nil
+	}
+	t.mu.Lock()
+	until, ok := t.exhausted[endpoint]
+	t.mu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	wait := time.Until(until)
+	if wait <= 0 || wait > retryMaxDelay {
+		// Either the quota has reset, or the wait is too long to absorb
+		// here; let the request through and report Datadog's answer.
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe records whether resp reports the quota of endpoint as used up.
+func (t *retryTransport) observe(endpoint string, resp *http.Response) {
+	rl, ok := parseRateLimit(resp)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if rl.Remaining > 0 && resp.StatusCode != http.StatusTooManyRequests {
+		delete(t.exhausted, endpoint)
+		return
+	}
+	t.exhausted[endpoint] = time.Now().Add(rl.Reset)
+}
+
+// isRetryable reports whether req may be sent more than once.
+func isRetryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	read, _ := req.Context().Value(readRequestKey{}).(bool)
+	return read
+}
+
+// shouldRetry reports whether a request that produced resp and err is worth
+// retrying.
+func shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, ErrNotRecorded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryDelay returns how long to wait before the next attempt. It reports
+// false when the rate limit resets too far in the future to wait for.
+func retryDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		if rl, ok := parseRateLimit(resp); ok {
+			if rl.Reset > retryMaxDelay {
+				return 0, false
+			}
+			// Spread retries over a short window after the reset so that
+			// concurrent callers do not all fire at the same instant.
+			return rl.Reset + rand.N(retryBaseDelay), true
+		}
+	}
+
+	// Exponential backoff with full jitter.
+	backoff := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return backoff/2 + rand.N(backoff/2), true
+}
@@ -0,0 +1,137 @@
+package datadog
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+)
+
+// rateLimited answers with a 429 whose quota resets in reset.
+func rateLimited(w http.ResponseWriter, reset time.Duration) {
+	w.Header().Set("X-RateLimit-Limit", "100")
+	w.Header().Set("X-RateLimit-Remaining", "0")
+	w.Header().Set("X-RateLimit-Period", "60")
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(reset.Seconds())))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	w.Write([]byte(`{"errors": ["Too many requests"]}`))
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return NewClient(config.Org{Site: "datadoghq.com", APIKey: "api", AppKey: "app"}, WithBaseURL(server.URL))
+}
+
+func TestRetryRateLimitWithinMaxDelay(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			rateLimited(w, 0)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "abc-123-def", "title": "Checkout Overview", "layout_type": "ordered", "widgets": []}`))
+	})
+
+	dashboard, err := client.GetDashboard(context.Background(), "abc-123-def")
+	if err != nil {
+		t.Fatalf("GetDashboard: %v", err)
+	}
+	if dashboard.Title != "Checkout Overview" {
+		t.Errorf("Title = %q, want Checkout Overview", dashboard.Title)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
+
+func TestRetryRateLimitBeyondMaxDelay(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		rateLimited(w, retryMaxDelay+time.Minute)
+	})
+
+	start := time.Now()
+	_, err := client.GetDashboard(context.Background(), "abc-123-def")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.Kind != ErrorRateLimited {
+		t.Errorf("Kind = %q, want %q", apiErr.Kind, ErrorRateLimited)
+	}
+	if apiErr.RateLimit == nil || apiErr.RateLimit.Reset != retryMaxDelay+time.Minute {
+		t.Errorf("RateLimit = %+v, want a reset in %s", apiErr.RateLimit, retryMaxDelay+time.Minute)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %s, want no wait", elapsed)
+	}
+}
+
+func TestRetrySkipsUnmarkedPost(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	transport := newRetryTransport(http.DefaultTransport)
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v2/query/timeseries", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+	if !isRetryable(req.WithContext(markRead(req.Context()))) {
+		t.Error("a POST marked as a read is not retryable")
+	}
+}
+
+func TestRetryBackoffCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rateLimited(w, retryMaxDelay)
+	}))
+	defer server.Close()
+	transport := newRetryTransport(http.DefaultTransport)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/query", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = transport.RoundTrip(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned %s after the request started, want promptly after cancellation", elapsed)
+	}
+}
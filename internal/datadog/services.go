@@ -2,7 +2,6 @@ package datadog
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
 )
@@ -42,12 +41,16 @@ type ListServicesResult struct {
 
 // ListServices lists all services from the Datadog service catalog.
 func (c *Client) ListServices(ctx context.Context) (*ListServicesResult, error) {
+	return cached(ctx, c.cache, "ListServices", cacheKey("ListServices"), c.listServices)
+}
+
+func (c *Client) listServices(ctx context.Context) (*ListServicesResult, error) {
 	opts := datadogV2.NewListServiceDefinitionsOptionalParameters().
 		WithSchemaVersion(datadogV2.SERVICEDEFINITIONSCHEMAVERSIONS_V2_2)
 
-	resp, _, err := c.serviceAPI.ListServiceDefinitions(c.ctx, *opts)
+	resp, httpResp, err := c.serviceAPI.ListServiceDefinitions(c.Context(ctx), *opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list services: %w", err)
+		return nil, apiError("failed to list services", httpResp, err)
 	}
 
 	result := &ListServicesResult{
@@ -0,0 +1,232 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadog"
+	"github.com/DataDog/datadog-api-client-go/v2/api/datadogV2"
+)
+
+// Data sources of timeseries queries. Metrics and cloud cost queries are
+// metric queries; the others search events.
+var (
+	MetricsDataSources = []string{"metrics", "cloud_cost"}
+	EventsDataSources  = []string{"logs", "rum", "dora"}
+)
+
+// Aggregators of timeseries queries, by kind of data source.
+var (
+	MetricsAggregators = []string{"avg", "sum", "min", "max"}
+	EventsAggregators  = []string{"count", "cardinality", "sum", "min", "max", "avg", "pc75", "pc90", "pc95", "pc98", "pc99"}
+)
+
+// aggregatorPrefix matches the space aggregator leading a metric query, such
+// as avg: or p95:.
+var aggregatorPrefix = regexp.MustCompile(`^[a-z][a-z0-9]*:`)
+
+// TimeseriesQuery is a named query of a timeseries request, which formulas
+// refer to by name.
+type TimeseriesQuery struct {
+	Name string `json:"name"`
+	// DataSource is one of MetricsDataSources or EventsDataSources. Empty
+	// means metrics.
+	DataSource string `json:"data_source,omitempty"`
+	// Query is a metric query such as system.cpu.user{env:prod} by {host},
+	// or an event search such as service:web status:error.
+	Query string `json:"query"`
+	// Aggregator is the space aggregator of a metric query, replacing the
+	// query's own, or the computation of an event query. Empty keeps the
+	// metric query's aggregator, or counts events.
+	Aggregator string `json:"aggregator,omitempty"`
+	// Metric is the measure an event query aggregates, e.g. @duration.
+	Metric string `json:"metric,omitempty"`
+	// GroupBy lists the facets an event query is grouped by.
+	GroupBy []string `json:"group_by,omitempty"`
+}
+
+// Events reports whether q searches events rather than metrics.
+func (q TimeseriesQuery) Events() bool {
+	return slices.Contains(EventsDataSources, q.DataSource)
+}
+
+// Validate checks the name, data source and aggregator of q.
+func (q TimeseriesQuery) Validate() error {
+	if q.Name == "" {
+		return fmt.Errorf("query %q has no name", q.Query)
+	}
+	if q.DataSource != "" && !q.Events() && !slices.Contains(MetricsDataSources, q.DataSource) {
+		return fmt.Errorf("query %q: invalid data source %q, use one of %s", q.Name, q.DataSource, strings.Join(append(slices.Clone(MetricsDataSources), EventsDataSources...), ", "))
+	}
+	aggregators := MetricsAggregators
+	if q.Events() {
+		aggregators = EventsAggregators
+	} else if q.Metric != "" || len(q.GroupBy) > 0 {
+		return fmt.Errorf("query %q: metric and group_by only apply to %s queries; group metric queries with by {tag} in the query", q.Name, strings.Join(EventsDataSources, ", "))
+	}
+	if q.Aggregator != "" && !slices.Contains(aggregators, q.Aggregator) {
+		return fmt.Errorf("query %q: invalid aggregator %q, use one of %s", q.Name, q.Aggregator, strings.Join(aggregators, ", "))
+	}
+	// The aggregators of queries wrapped in functions, e.g.
+	// abs(avg:system.load.1{*}), are inside the functions.
+	if !q.Events() && q.Aggregator != "" {
+		metric, _, _ := strings.Cut(q.Query, "{")
+		if strings.Contains(metric, "(") {
+			return fmt.Errorf("query %q: aggregator cannot replace the aggregators of a query wrapped in functions; set them in the query instead", q.Name)
+		}
+	}
+	return nil
+}
+
+// MetricQuery returns the metric query of q, with q's aggregator in place of
+// the aggregator leading the query, if any.
+func (q TimeseriesQuery) MetricQuery() string {
+	if q.Aggregator == "" {
+		return q.Query
+	}
+	query := strings.TrimSpace(q.Query)
+	if loc := aggregatorPrefix.FindStringIndex(query); loc != nil {
+		query = query[loc[1]:]
+	}
+	return q.Aggregator + ":" + query
+}
+
+// QueryTimeseriesResult contains the result of a timeseries query: the series
+// of each formula, or of each query when there are no formulas.
+type QueryTimeseriesResult struct {
+	Series   []MetricSeries    `json:"series"`
+	Queries  []TimeseriesQuery `json:"queries"`
+	Formulas []string          `json:"formulas,omitempty"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	// Interval is the time between points in seconds, as chosen by Datadog
+	// unless the request set one.
+	Interval    int64 `json:"interval,omitempty"`
+	TotalSeries int   `json:"total_series,omitempty"`
+	Truncated   bool  `json:"truncated,omitempty"`
+	// Downsample is the strategy that fit series into the data point limit,
+	// when any series had more points.
+	Downsample string `json:"downsample,omitempty"`
+	// Warnings holds the errors Datadog reported alongside partial results.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// QueryTimeseries evaluates formulas over named metric and event queries
+// with the v2 timeseries API. A zero interval lets Datadog pick one for the
+// range. Series are named after their formula, or their query when there
+// are no formulas.
+func (c *Client) QueryTimeseries(ctx context.Context, queries []TimeseriesQuery, formulas []string, from, to time.Time, interval time.Duration) (*QueryTimeseriesResult, error) {
+	attributes := datadogV2.TimeseriesFormulaRequestAttributes{
+		From: from.UnixMilli(),
+		To:   to.UnixMilli(),
+	}
+	if interval > 0 {
+		attributes.Interval = datadog.PtrInt64(interval.Milliseconds())
+	}
+	for _, q := range queries {
+		if err := q.Validate(); err != nil {
+			return nil, err
+		}
+		attributes.Queries = append(attributes.Queries, timeseriesQuery(q, interval))
+	}
+	for _, f := range formulas {
+		attributes.Formulas = append(attributes.Formulas, *datadogV2.NewQueryFormula(f))
+	}
+	body := datadogV2.NewTimeseriesFormulaQueryRequest(*datadogV2.NewTimeseriesFormulaRequest(attributes, datadogV2.TIMESERIESFORMULAREQUESTTYPE_TIMESERIES_REQUEST))
+
+	resp, httpResp, err := c.metricsV2.QueryTimeseriesData(markRead(c.Context(ctx)), *body)
+	if err != nil {
+		return nil, apiError("failed to query timeseries", httpResp, err)
+	}
+
+	result := &QueryTimeseriesResult{
+		Series:   make([]MetricSeries, 0),
+		Queries:  queries,
+		Formulas: formulas,
+		From:     from,
+		To:       to,
+	}
+	if msg := resp.GetErrors(); msg != "" {
+		result.Warnings = []string{msg}
+	}
+
+	attrs := resp.GetData().Attributes
+	if attrs == nil {
+		return result, nil
+	}
+	times := attrs.GetTimes()
+	if len(times) > 1 {
+		result.Interval = (times[1] - times[0]) / 1000
+	}
+	values := attrs.GetValues()
+	for i, series := range attrs.GetSeries() {
+		ms := MetricSeries{
+			Tags:       series.GetGroupTags(),
+			DataPoints: make([]MetricPoint, 0),
+		}
+		index := int(series.GetQueryIndex())
+		switch {
+		case len(formulas) > 0 && index < len(formulas):
+			ms.Metric = formulas[index]
+		case len(formulas) == 0 && index < len(queries):
+			ms.Metric = queries[index].Name
+		}
+		if unit := series.GetUnit(); len(unit) > 0 {
+			ms.Unit = unit[0].GetName()
+			if len(unit) > 1 {
+				ms.PerUnit = unit[1].GetName()
+			}
+		}
+		if i < len(values) {
+			for j, v := range values[i] {
+				if v != nil && j < len(times) {
+					ms.DataPoints = append(ms.DataPoints, MetricPoint{
+						Timestamp: time.UnixMilli(times[j]),
+						Value:     *v,
+					})
+				}
+			}
+		}
+		result.Series = append(result.Series, ms)
+	}
+
+	return result, nil
+}
+
+// timeseriesQuery converts q, which must be valid, to its API form.
+func timeseriesQuery(q TimeseriesQuery, interval time.Duration) datadogV2.TimeseriesQuery {
+	if !q.Events() {
+		source := datadogV2.METRICSDATASOURCE_METRICS
+		if q.DataSource != "" {
+			source = datadogV2.MetricsDataSource(q.DataSource)
+		}
+		query := datadogV2.NewMetricsTimeseriesQuery(source, q.MetricQuery())
+		query.Name = datadog.PtrString(q.Name)
+		return datadogV2.MetricsTimeseriesQueryAsTimeseriesQuery(query)
+	}
+
+	aggregation := datadogV2.EVENTSAGGREGATION_COUNT
+	if q.Aggregator != "" {
+		aggregation = datadogV2.EventsAggregation(q.Aggregator)
+	}
+	compute := datadogV2.NewEventsCompute(aggregation)
+	if q.Metric != "" {
+		compute.Metric = datadog.PtrString(q.Metric)
+	}
+	if interval > 0 {
+		compute.Interval = datadog.PtrInt64(interval.Milliseconds())
+	}
+	query := datadogV2.NewEventsTimeseriesQuery(*compute, datadogV2.EventsDataSource(q.DataSource))
+	query.Name = datadog.PtrString(q.Name)
+	if q.Query != "" {
+		query.Search = &datadogV2.EventsSearch{Query: datadog.PtrString(q.Query)}
+	}
+	for _, facet := range q.GroupBy {
+		query.GroupBy = append(query.GroupBy, *datadogV2.NewEventsGroupBy(facet))
+	}
+	return datadogV2.EventsTimeseriesQueryAsTimeseriesQuery(query)
+}
@@ -0,0 +1,177 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTimeseriesQueryMetricQuery(t *testing.T) {
+	tests := []struct {
+		query, aggregator, want string
+	}{
+		{"system.cpu.user{*}", "", "system.cpu.user{*}"},
+		{"system.cpu.user{*}", "max", "max:system.cpu.user{*}"},
+		{"avg:system.cpu.user{env:prod} by {host}", "sum", "sum:system.cpu.user{env:prod} by {host}"},
+		{"p95:trace.web.request{service:web}", "max", "max:trace.web.request{service:web}"},
+		{"sum:trace.web.hits{*}.as_count()", "max", "max:trace.web.hits{*}.as_count()"},
+	}
+	for _, tt := range tests {
+		q := TimeseriesQuery{Name: "a", Query: tt.query, Aggregator: tt.aggregator}
+		if err := q.Validate(); err != nil {
+			t.Errorf("Validate(%q, %q) = %v", tt.query, tt.aggregator, err)
+		}
+		if got := q.MetricQuery(); got != tt.want {
+			t.Errorf("MetricQuery(%q, %q) = %q, want %q", tt.query, tt.aggregator, got, tt.want)
+		}
+	}
+}
+
+func TestTimeseriesQueryValidateFunctions(t *testing.T) {
+	q := TimeseriesQuery{Name: "a", Query: "abs(avg:system.load.1{*})"}
+	if err := q.Validate(); err != nil {
+		t.Fatalf("Validate without aggregator = %v", err)
+	}
+	if got := q.MetricQuery(); got != q.Query {
+		t.Errorf("MetricQuery = %q, want %q", got, q.Query)
+	}
+
+	q.Aggregator = "max"
+	if err := q.Validate(); err == nil {
+		t.Error("Validate with an aggregator over a function succeeded")
+	}
+}
+
+func TestQueryTimeseries(t *testing.T) {
+	var request struct {
+		Data struct {
+			Attributes struct {
+				From     int64 `json:"from"`
+				To       int64 `json:"to"`
+				Interval int64 `json:"interval"`
+				Queries  []struct {
+					Name  string `json:"name"`
+					Query string `json:"query"`
+				} `json:"queries"`
+				Formulas []struct {
+					Formula string `json:"formula"`
+				} `json:"formulas"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/query/timeseries" {
+			t.Errorf("request to %s, want /api/v2/query/timeseries", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"data": {
+				"type": "timeseries_response",
+				"attributes": {
+					"times": [1736942400000, 1736944200000, 1736946000000],
+					"series": [
+						{"query_index": 1, "group_tags": ["host:web-1"], "unit": [{"name": "byte"}]},
+						{"query_index": 0, "group_tags": ["host:web-1"], "unit": [{"name": "percent"}, null]},
+						{"query_index": 0, "group_tags": ["host:web-2"]}
+					],
+					"values": [
+						[1024, 2048, 4096],
+						[10, null, 30],
+						[null, null]
+					]
+				}
+			},
+			"errors": "query b: some groups timed out"
+		}`))
+	})
+
+	from := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	queries := []TimeseriesQuery{
+		{Name: "a", Query: "avg:system.cpu.user{*} by {host}"},
+		{Name: "b", Query: "system.mem.used{*} by {host}", Aggregator: "max"},
+	}
+	result, err := client.QueryTimeseries(context.Background(), queries, []string{"a * 2", "b"}, from, to, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attrs := request.Data.Attributes
+	if attrs.From != from.UnixMilli() || attrs.To != to.UnixMilli() || attrs.Interval != 1800000 {
+		t.Errorf("request from, to, interval = %d, %d, %d", attrs.From, attrs.To, attrs.Interval)
+	}
+	if len(attrs.Queries) != 2 || attrs.Queries[1].Name != "b" || attrs.Queries[1].Query != "max:system.mem.used{*} by {host}" {
+		t.Errorf("request queries = %+v", attrs.Queries)
+	}
+	if len(attrs.Formulas) != 2 || attrs.Formulas[0].Formula != "a * 2" {
+		t.Errorf("request formulas = %+v", attrs.Formulas)
+	}
+
+	if result.Interval != 1800 {
+		t.Errorf("Interval = %d, want 1800", result.Interval)
+	}
+	if !slices.Equal(result.Warnings, []string{"query b: some groups timed out"}) {
+		t.Errorf("Warnings = %q", result.Warnings)
+	}
+	if len(result.Series) != 3 {
+		t.Fatalf("got %d series, want 3", len(result.Series))
+	}
+
+	// query_index refers to the formulas, and null values are dropped with
+	// their timestamps.
+	tests := []struct {
+		metric, unit, tag string
+		times             []int64
+		values            []float64
+	}{
+		{"b", "byte", "host:web-1", []int64{1736942400000, 1736944200000, 1736946000000}, []float64{1024, 2048, 4096}},
+		{"a * 2", "percent", "host:web-1", []int64{1736942400000, 1736946000000}, []float64{10, 30}},
+		{"a * 2", "", "host:web-2", nil, nil},
+	}
+	for i, tt := range tests {
+		s := result.Series[i]
+		var times []int64
+		var values []float64
+		for _, p := range s.DataPoints {
+			times = append(times, p.Timestamp.UnixMilli())
+			values = append(values, p.Value)
+		}
+		if s.Metric != tt.metric || s.Unit != tt.unit || !slices.Equal(s.Tags, []string{tt.tag}) || !slices.Equal(times, tt.times) || !slices.Equal(values, tt.values) {
+			t.Errorf("series %d = %s %q %v %v %v, want %s %q [%s] %v %v", i, s.Metric, s.Unit, s.Tags, times, values, tt.metric, tt.unit, tt.tag, tt.times, tt.values)
+		}
+	}
+}
+
+func TestQueryTimeseriesWithoutFormulas(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"type": "timeseries_response", "attributes": {
+			"times": [1736942400000],
+			"series": [{"query_index": 1}, {"query_index": 0}, {"query_index": 2}],
+			"values": [[1], [2], [3]]
+		}}}`))
+	})
+
+	queries := []TimeseriesQuery{{Name: "cpu", Query: "avg:system.cpu.user{*}"}, {Name: "mem", Query: "avg:system.mem.used{*}"}}
+	result, err := client.QueryTimeseries(context.Background(), queries, nil, time.Unix(0, 0), time.Unix(3600, 0), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range result.Series {
+		names = append(names, s.Metric)
+	}
+	// An index past the queries leaves the series unnamed.
+	if !slices.Equal(names, []string{"mem", "cpu", ""}) {
+		t.Errorf("series = %q, want [mem cpu \"\"]", names)
+	}
+	if result.Interval != 0 || result.Warnings != nil {
+		t.Errorf("Interval, Warnings = %d, %q; want none", result.Interval, result.Warnings)
+	}
+}
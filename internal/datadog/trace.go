@@ -0,0 +1,108 @@
+package datadog
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+// UpstreamCall describes one HTTP request made to the Datadog API.
+type UpstreamCall struct {
+	Method   string        `json:"method"`
+	Endpoint string        `json:"endpoint"`
+	Status   int           `json:"status,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Trace collects the Datadog API requests made on behalf of one caller,
+// including retried attempts.
+type Trace struct {
+	mu     sync.Mutex
+	calls  []UpstreamCall
+	queued time.Duration
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context that records the Datadog API requests made with
+// it into the returned Trace.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	t := &Trace{}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+// Calls returns the requests recorded so far.
+func (t *Trace) Calls() []UpstreamCall {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.calls)
+}
+
+// Queued returns how long requests waited for a free slot under the
+// client's concurrency limits.
+func (t *Trace) Queued() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.queued
+}
+
+func (t *Trace) addQueued(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queued += d
+}
+
+func (t *Trace) add(call UpstreamCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.calls = append(t.calls, call)
+}
+
+// traceTransport records every request attempt in the server's metrics,
+// labelled with org, and into the Trace carried by the request context, if
+// any.
+type traceTransport struct {
+	next http.RoundTripper
+	org  string
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route := Route(req.URL.Path)
+
+	inFlight := telemetry.UpstreamInFlight.WithLabelValues(t.org)
+	inFlight.Inc()
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	duration := time.Since(start)
+	inFlight.Dec()
+
+	code := "error"
+	if resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	telemetry.UpstreamRequests.WithLabelValues(t.org, route, req.Method, code).Inc()
+	telemetry.UpstreamDuration.WithLabelValues(t.org, route).Observe(duration.Seconds())
+
+	if trace, _ := req.Context().Value(traceKey{}).(*Trace); trace != nil {
+		call := UpstreamCall{
+			Method:   req.Method,
+			Endpoint: req.URL.Path,
+			Duration: duration,
+		}
+		if resp != nil {
+			call.Status = resp.StatusCode
+		}
+		if err != nil {
+			call.Error = err.Error()
+		}
+		trace.add(call)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,41 @@
+package datadog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pedrospdc/datadog-mcp/internal/config"
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+func TestTraceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "abc-123-def", "title": "Checkout Overview", "layout_type": "ordered", "widgets": []}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewClient(config.Org{Name: "eu1", Site: "datadoghq.eu", APIKey: "api", AppKey: "app"}, WithBaseURL(server.URL))
+	requests := telemetry.UpstreamRequests.WithLabelValues("eu1", "/api/v1/dashboard/{dashboard_id}", http.MethodGet, "200")
+	misses := telemetry.CacheRequests.WithLabelValues("eu1", "GetDashboard", "miss")
+	before, missesBefore := testutil.ToFloat64(requests), testutil.ToFloat64(misses)
+
+	ctx, trace := WithTrace(context.Background())
+	if _, err := client.GetDashboard(ctx, "abc-123-def"); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := trace.Calls()
+	if len(calls) != 1 || calls[0].Endpoint != "/api/v1/dashboard/abc-123-def" || calls[0].Status != http.StatusOK {
+		t.Errorf("calls = %+v, want one successful dashboard request", calls)
+	}
+	if got := testutil.ToFloat64(requests) - before; got != 1 {
+		t.Errorf("counted %v requests to the dashboard route of eu1, want 1", got)
+	}
+	if got := testutil.ToFloat64(misses) - missesBefore; got != 1 {
+		t.Errorf("counted %v cache misses of eu1, want 1", got)
+	}
+}
@@ -0,0 +1,67 @@
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// Format is the format of a summary.
+type Format string
+
+const (
+	// FormatText is compact plain text.
+	FormatText Format = "text"
+	// FormatMarkdown writes lists as markdown tables.
+	FormatMarkdown Format = "markdown"
+	// FormatCSV writes lists as CSV, apart from the text accompanying them.
+	FormatCSV Format = "csv"
+	// FormatJSON is the structured output of a tool, as JSON.
+	FormatJSON Format = "json"
+)
+
+// ParseFormat parses an output format. The empty string means FormatText.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatMarkdown, FormatCSV, FormatJSON:
+		return f, nil
+	default:
+		return "", fmt.Errorf("invalid output_format %q: must be text, markdown, csv or json", s)
+	}
+}
+
+// Tabular reports whether lists are written as tables in format f.
+func (f Format) Tabular() bool {
+	return f == FormatMarkdown || f == FormatCSV
+}
+
+// markdownRow renders cells as a markdown table row.
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		escaped[i] = strings.Join(strings.Fields(cell), " ")
+	}
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+// markdownHeader renders the header and delimiter rows of a markdown table.
+func markdownHeader(columns []string) string {
+	delims := make([]string, len(columns))
+	for i := range delims {
+		delims[i] = "---"
+	}
+	return markdownRow(columns) + "|" + strings.Join(delims, "|") + "|\n"
+}
+
+// csvRows renders rows as CSV records.
+func csvRows(rows ...[]string) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	// Writing to a bytes.Buffer cannot fail.
+	_ = w.WriteAll(rows)
+	return b.String()
+}
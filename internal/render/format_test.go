@@ -0,0 +1,86 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatText, "text": FormatText, "markdown": FormatMarkdown, "csv": FormatCSV, "json": FormatJSON} {
+		if got, err := ParseFormat(in); got != want || err != nil {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Error("ParseFormat(yaml) succeeded, want an error")
+	}
+}
+
+// hosts returns a list of hosts with one table row each.
+func hosts(names ...string) List {
+	return List{
+		Noun:  "hosts",
+		Count: len(names),
+		Item: func(i int, detail Detail) string {
+			return names[i] + "\n"
+		},
+		Columns: []string{"host", "role"},
+		Rows: func(i int) [][]string {
+			return [][]string{{names[i], "web|api"}}
+		},
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	text := NewText(8000, DetailNormal, FormatMarkdown)
+	text.Printf("Hosts: 2\n")
+	text.List(hosts("web-1", "web 2\n"))
+	want := "Hosts: 2\n| host | role |\n|---|---|\n| web-1 | web\\|api |\n| web 2 | web\\|api |\n"
+	if got := text.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCSVParts(t *testing.T) {
+	text := NewText(8000, DetailNormal, FormatCSV)
+	text.Printf("Hosts: 2\n")
+	text.List(hosts("web-1", "web,2"))
+	text.List(hosts("db-1"))
+	text.Note("a note")
+
+	want := []string{
+		"host,role\nweb-1,web|api\n\"web,2\",web|api\n\nhost,role\ndb-1,web|api\n",
+		"Hosts: 2\n\na note",
+	}
+	if got := text.Parts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parts() = %q, want %q", got, want)
+	}
+}
+
+func TestTableBudget(t *testing.T) {
+	names := make([]string, 50)
+	for i := range names {
+		names[i] = strings.Repeat("h", 20)
+	}
+	text := NewText(400, DetailNormal, FormatMarkdown)
+	text.List(hosts(names...))
+
+	got := text.String()
+	if rows := strings.Count(got, "| hhh"); rows == 0 || rows == len(names) {
+		t.Errorf("listed %d of %d rows, want the table cut to the budget:\n%s", rows, len(names), got)
+	}
+	if !strings.Contains(got, "of 50 hosts") || !strings.Contains(got, "to fit the 400-character output budget; see the structured output.") {
+		t.Errorf("summary does not note the omitted rows:\n%s", got)
+	}
+}
+
+func TestListWithoutColumns(t *testing.T) {
+	l := hosts("web-1")
+	l.Columns = nil
+	text := NewText(8000, DetailNormal, FormatMarkdown)
+	text.List(l)
+	if got := text.String(); got != "web-1\n" {
+		t.Errorf("String() = %q, want the list written as text", got)
+	}
+}
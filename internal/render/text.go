@@ -0,0 +1,254 @@
+// Package render builds the text summaries tools return, in the requested
+// format and within a size budget.
+package render
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Detail controls how much of a result a text summary spells out.
+type Detail string
+
+const (
+	// DetailBrief lists one line per item.
+	DetailBrief Detail = "brief"
+	// DetailNormal lists the main attributes of each item.
+	DetailNormal Detail = "normal"
+	// DetailFull lists every attribute of each item.
+	DetailFull Detail = "full"
+)
+
+// ParseDetail parses a detail level. The empty string means DetailNormal.
+func ParseDetail(s string) (Detail, error) {
+	switch d := Detail(s); d {
+	case "":
+		return DetailNormal, nil
+	case DetailBrief, DetailNormal, DetailFull:
+		return d, nil
+	default:
+		return "", fmt.Errorf("invalid detail %q: must be brief, normal or full", s)
+	}
+}
+
+// noteReserve is the part of the budget kept free for the notes stating what
+// was left out. Small budgets keep a quarter of their size instead.
+const noteReserve = 400
+
+// Text builds a text summary that stays within a character budget. Content
+// that does not fit is collapsed or left out, and the summary ends with notes
+// stating exactly what was omitted and how to retrieve it.
+//
+// In the CSV format, the summary is split in two parts: the lists, as CSV,
+// and the text written with Printf followed by the notes.
+type Text struct {
+	limit  int
+	detail Detail
+	format Format
+	b      strings.Builder
+	// prose holds the text written with Printf in the CSV format.
+	prose  strings.Builder
+	tables int
+	notes  []string
+}
+
+// NewText returns a summary builder with a budget of limit characters.
+func NewText(limit int, detail Detail, format Format) *Text {
+	return &Text{limit: limit, detail: detail, format: format}
+}
+
+// Detail returns the requested detail level.
+func (t *Text) Detail() Detail {
+	return t.detail
+}
+
+// Format returns the requested format.
+func (t *Text) Format() Format {
+	return t.format
+}
+
+// Printf writes text that is always kept, such as headers and totals.
+func (t *Text) Printf(format string, args ...any) {
+	fmt.Fprintf(t.text(), format, args...)
+}
+
+// Note adds a note to the end of the summary.
+func (t *Text) Note(format string, args ...any) {
+	t.notes = append(t.notes, fmt.Sprintf(format, args...))
+}
+
+// Section writes s if it fits in the budget. Otherwise it notes that what
+// describes was omitted and how to retrieve it, and reports false.
+func (t *Text) Section(s, what, retrieve string) bool {
+	if t.fits(s) {
+		t.text().WriteString(s)
+		return true
+	}
+	t.Note("Omitted %s to fit the %d-character output budget; %s.", what, t.limit, retrieve)
+	return false
+}
+
+// List describes a list of items to write into a summary.
+type List struct {
+	// Noun names the items, in the plural, e.g. "spans".
+	Noun string
+	// Count is the number of items available.
+	Count int
+	// Max caps the number of items listed. Zero means no cap.
+	Max int
+	// Item renders item i at the given detail level. At DetailBrief it
+	// should return a single line.
+	Item func(i int, detail Detail) string
+	// Columns names the table columns, and Rows renders item i as table
+	// rows, in the markdown and CSV formats. Lists without Columns are
+	// written as in the text format.
+	Columns []string
+	Rows    func(i int) [][]string
+	// Retrieve tells the reader how to get the items that were not listed,
+	// starting with item first (0-based), e.g. "call again with start=100".
+	// Without it, the reader is pointed at the structured output, which
+	// holds every item.
+	Retrieve func(first int) string
+}
+
+// List writes the items of l in order. Items are written at the requested
+// detail level while the budget allows, then collapsed to one line each, and
+// the rest are left out. Collapsed and omitted items are reported in the
+// notes.
+//
+// In the markdown and CSV formats, lists with Columns are written as tables
+// instead, keeping whole items while the budget allows.
+func (t *Text) List(l List) {
+	n := l.Count
+	if l.Max > 0 && n > l.Max {
+		n = l.Max
+	}
+
+	collapsed := 0
+	listed := 0
+	if t.format.Tabular() && l.Columns != nil {
+		listed = t.table(l, n)
+	} else {
+		for ; listed < n; listed++ {
+			s := l.Item(listed, t.detail)
+			if !t.fits(s) && t.detail != DetailBrief {
+				s = l.Item(listed, DetailBrief)
+				if t.fits(s) {
+					collapsed++
+				}
+			}
+			if !t.fits(s) {
+				break
+			}
+			t.text().WriteString(s)
+		}
+	}
+
+	if collapsed > 0 {
+		t.Note("%d of the %d %s listed were collapsed to one line each to fit the %d-character output budget; raise max_output_chars to see them in full.",
+			collapsed, listed, l.Noun, t.limit)
+	}
+	if omitted := l.Count - listed; omitted > 0 {
+		reason := fmt.Sprintf("to fit the %d-character output budget", t.limit)
+		if listed == n {
+			reason = fmt.Sprintf("beyond the listing limit of %d", l.Max)
+		}
+		retrieve := "see the structured output"
+		if l.Retrieve != nil {
+			retrieve = l.Retrieve(listed)
+		}
+		t.Note("Omitted %d of %d %s (#%d-#%d) %s; %s.", omitted, l.Count, l.Noun, listed+1, l.Count, reason, retrieve)
+	}
+}
+
+// table writes the first n items of l as a table, and returns how many
+// were written.
+func (t *Text) table(l List, n int) int {
+	var header string
+	switch {
+	case t.format == FormatCSV:
+		if t.tables > 0 {
+			header = "\n"
+		}
+		header += csvRows(l.Columns)
+	default:
+		header = markdownHeader(l.Columns)
+	}
+	if n == 0 || !t.fits(header) {
+		return 0
+	}
+	t.b.WriteString(header)
+	t.tables++
+
+	listed := 0
+	for ; listed < n; listed++ {
+		rows := l.Rows(listed)
+		var s string
+		if t.format == FormatCSV {
+			s = csvRows(rows...)
+		} else {
+			for _, row := range rows {
+				s += markdownRow(row)
+			}
+		}
+		if !t.fits(s) {
+			break
+		}
+		t.b.WriteString(s)
+	}
+	return listed
+}
+
+// String returns the parts of the summary, separated by a blank line.
+func (t *Text) String() string {
+	return strings.Join(t.Parts(), "\n\n")
+}
+
+// Parts returns the summary as separate blocks of text: the text followed by
+// its notes, preceded in the CSV format by the CSV data.
+func (t *Text) Parts() []string {
+	text := t.text()
+	s := text.String()
+	notes := t.notes
+	if over := t.b.Len() + t.prose.Len() - t.limit; over > 0 && over < len(s) {
+		// Only text written with Printf can get here; cut it rather than
+		// blow the budget.
+		cut := strings.LastIndexByte(s[:len(s)-over], '\n')
+		if cut <= 0 {
+			cut = len(s) - over
+			for cut > 0 && !utf8.RuneStart(s[cut]) {
+				cut--
+			}
+		}
+		notes = append([]string{fmt.Sprintf("Cut %d characters to fit the %d-character output budget; raise max_output_chars to see everything.", len(s)-cut, t.limit)}, notes...)
+		s = s[:cut]
+	}
+	if len(notes) > 0 {
+		s = strings.TrimRight(s, "\n") + "\n\n" + strings.Join(notes, "\n")
+	}
+
+	if text == &t.b {
+		return []string{s}
+	}
+	var parts []string
+	if t.b.Len() > 0 {
+		parts = append(parts, t.b.String())
+	}
+	if s = strings.TrimSpace(s); s != "" {
+		parts = append(parts, s)
+	}
+	return parts
+}
+
+// text returns the builder that text written with Printf goes to.
+func (t *Text) text() *strings.Builder {
+	if t.format == FormatCSV {
+		return &t.prose
+	}
+	return &t.b
+}
+
+func (t *Text) fits(s string) bool {
+	return t.b.Len()+t.prose.Len()+len(s)+min(noteReserve, t.limit/4) <= t.limit
+}
@@ -0,0 +1,174 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseDetail(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Detail
+		err  bool
+	}{
+		{"", DetailNormal, false},
+		{"brief", DetailBrief, false},
+		{"normal", DetailNormal, false},
+		{"full", DetailFull, false},
+		{"verbose", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDetail(tt.in)
+		if got != tt.want || (err != nil) != tt.err {
+			t.Errorf("ParseDetail(%q) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+// items returns a list of n items, each rendered on one line at DetailBrief
+// and padded to size characters otherwise.
+func items(n, size int) List {
+	return List{
+		Noun:  "spans",
+		Count: n,
+		Item: func(i int, detail Detail) string {
+			line := fmt.Sprintf("[%d] %s\n", i+1, detail)
+			if detail == DetailBrief {
+				return line
+			}
+			return line + strings.Repeat("x", size) + "\n"
+		},
+		Retrieve: func(first int) string {
+			return fmt.Sprintf("page from %d", first)
+		},
+	}
+}
+
+func TestList(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		detail  Detail
+		list    List
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "everything fits",
+			limit:  8000,
+			detail: DetailNormal,
+			list:   items(3, 10),
+			want:   []string{"[1] normal\n", "[3] normal\n"},
+			notWant: []string{
+				"Omitted",
+				"collapsed",
+			},
+		},
+		{
+			name:   "brief",
+			limit:  8000,
+			detail: DetailBrief,
+			list:   items(3, 10),
+			want:   []string{"[1] brief\n[2] brief\n[3] brief"},
+		},
+		{
+			name:   "full",
+			limit:  8000,
+			detail: DetailFull,
+			list:   items(2, 10),
+			want:   []string{"[1] full\nxxxxxxxxxx\n[2] full"},
+		},
+		{
+			name:   "collapsed",
+			limit:  1000,
+			detail: DetailNormal,
+			list:   items(5, 200),
+			want: []string{
+				"[3] normal\n",
+				"[4] brief\n[5] brief\n",
+				"2 of the 5 spans listed were collapsed to one line each to fit the 1000-character output budget",
+			},
+			notWant: []string{"Omitted"},
+		},
+		{
+			name:   "omitted for the budget",
+			limit:  100,
+			detail: DetailBrief,
+			list:   items(20, 0),
+			want: []string{
+				"[1] brief\n",
+				"to fit the 100-character output budget; page from ",
+			},
+			notWant: []string{"[20] brief"},
+		},
+		{
+			name:   "omitted beyond the listing limit",
+			limit:  8000,
+			detail: DetailBrief,
+			list:   func() List { l := items(5, 0); l.Max = 2; return l }(),
+			want:   []string{"Omitted 3 of 5 spans (#3-#5) beyond the listing limit of 2; page from 2."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := NewText(tt.limit, tt.detail, FormatText)
+			text.List(tt.list)
+			got := text.String()
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("summary does not contain %q:\n%s", s, got)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("summary contains %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSection(t *testing.T) {
+	text := NewText(200, DetailNormal, FormatText)
+	if !text.Section("Widgets: 3\n", "widgets", "raise max_output_chars") {
+		t.Error("small section was not written")
+	}
+	if text.Section(strings.Repeat("x", 200), "the widget list", "raise max_output_chars") {
+		t.Error("oversized section was written")
+	}
+	want := "Widgets: 3\n\nOmitted the widget list to fit the 200-character output budget; raise max_output_chars."
+	if got := text.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestFitsReserve(t *testing.T) {
+	// Large budgets keep noteReserve characters free, small ones a quarter
+	// of their size.
+	large := NewText(2000, DetailNormal, FormatText)
+	if !large.fits(strings.Repeat("x", 2000-noteReserve)) || large.fits(strings.Repeat("x", 2000-noteReserve+1)) {
+		t.Errorf("budget of 2000 does not keep %d characters free", noteReserve)
+	}
+	small := NewText(100, DetailNormal, FormatText)
+	if !small.fits(strings.Repeat("x", 75)) || small.fits(strings.Repeat("x", 76)) {
+		t.Error("budget of 100 does not keep 25 characters free")
+	}
+}
+
+func TestStringCut(t *testing.T) {
+	text := NewText(30, DetailNormal, FormatText)
+	text.Printf("first line\nsecond line\nthird line is long\n")
+	text.Note("a note")
+	want := "first line\nsecond line\n\nCut 20 characters to fit the 30-character output budget; raise max_output_chars to see everything.\na note"
+	if got := text.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Without a line break to cut at, cut on a rune boundary.
+	text = NewText(5, DetailNormal, FormatText)
+	text.Printf("ééééé")
+	if got := text.String(); !strings.HasPrefix(got, "éé\n\nCut 6 characters") {
+		t.Errorf("String() = %q, want two runes kept", got)
+	}
+}
@@ -0,0 +1,104 @@
+// Package telemetry holds the Prometheus metrics describing the server's own
+// behaviour: tool calls, upstream Datadog requests and cache efficiency.
+// Upstream and cache metrics carry the name of the Datadog org they belong
+// to, since each org has its own rate limits, concurrency limits and cache.
+package telemetry
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "datadog_mcp"
+
+var (
+	// ToolCalls counts tool calls by tool and outcome ("ok" or an error class).
+	ToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_total",
+		Help:      "Tool calls handled, by tool and outcome.",
+	}, []string{"tool", "outcome"})
+
+	// ToolDuration observes how long tool calls take, by tool.
+	ToolDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "tool_call_duration_seconds",
+		Help:      "Duration of tool calls, by tool.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"tool"})
+
+	// ToolsInFlight tracks tool calls currently being handled, by tool.
+	ToolsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "tool_calls_in_flight",
+		Help:      "Tool calls currently being handled, by tool.",
+	}, []string{"tool"})
+
+	// UpstreamRequests counts Datadog API requests by org, endpoint, method
+	// and status code. Network failures are recorded with code "error".
+	UpstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Datadog API requests, by org, endpoint, method and status code.",
+	}, []string{"org", "endpoint", "method", "code"})
+
+	// UpstreamDuration observes Datadog API request latency, by org and
+	// endpoint.
+	UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Duration of Datadog API requests, by org and endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"org", "endpoint"})
+
+	// UpstreamInFlight tracks Datadog API requests currently in flight, by
+	// org.
+	UpstreamInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_in_flight",
+		Help:      "Datadog API requests currently in flight, by org.",
+	}, []string{"org"})
+
+	// UpstreamQueueDuration observes how long Datadog API requests wait for a
+	// free slot under the concurrency limits, by org and endpoint family.
+	UpstreamQueueDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_queue_duration_seconds",
+		Help:      "Time Datadog API requests spend waiting for a concurrency slot, by org and endpoint family.",
+		Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"org", "family"})
+
+	// CacheRequests counts response cache lookups by org, method and result
+	// ("hit", "miss" or "bypass").
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Response cache lookups, by org, client method and result.",
+	}, []string{"org", "method", "result"})
+)
+
+// Registry holds every metric exported by the server.
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		ToolCalls,
+		ToolDuration,
+		ToolsInFlight,
+		UpstreamRequests,
+		UpstreamDuration,
+		UpstreamInFlight,
+		UpstreamQueueDuration,
+		CacheRequests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,130 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// secretArgPattern matches argument names whose values must not be logged.
+// It matches whole names, so that arguments such as a tag "key" are kept.
+var secretArgPattern = regexp.MustCompile(`(?i)^(api_?key|app_?key|application_?key|(\w+_)?token|(\w+_)?secret|password|passwd|authorization|credentials?)$`)
+
+const redacted = "[REDACTED]"
+
+// auditRecord describes one tool invocation for the audit log.
+type auditRecord struct {
+	tool     string
+	session  string
+	args     json.RawMessage
+	start    time.Time
+	upstream []datadog.UpstreamCall
+	queued   time.Duration
+	// size is the size in bytes of the result returned to the client.
+	size int
+	err  error
+	ctx  context.Context
+}
+
+// log writes the record to logger as a single structured entry.
+func (a auditRecord) log(logger *slog.Logger) {
+	attrs := []slog.Attr{
+		slog.String("tool", a.tool),
+		slog.Any("arguments", redactArgs(a.args)),
+		slog.Float64("duration_ms", float64(time.Since(a.start).Microseconds())/1000),
+		slog.Int("result_bytes", a.size),
+	}
+	if a.session != "" {
+		attrs = append(attrs, slog.String("session", a.session))
+	}
+
+	upstream := make([]map[string]any, 0, len(a.upstream))
+	for _, call := range a.upstream {
+		entry := map[string]any{
+			"method":      call.Method,
+			"endpoint":    call.Endpoint,
+			"duration_ms": float64(call.Duration.Microseconds()) / 1000,
+		}
+		if call.Status != 0 {
+			entry["status"] = call.Status
+		}
+		if call.Error != "" {
+			entry["error"] = call.Error
+		}
+		upstream = append(upstream, entry)
+	}
+	attrs = append(attrs, slog.Any("upstream", upstream))
+	if a.queued > 0 {
+		attrs = append(attrs, slog.Float64("queued_ms", float64(a.queued.Microseconds())/1000))
+	}
+
+	level := slog.LevelInfo
+	if a.err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs,
+			slog.String("error_class", errorClass(a.ctx, a.err, a.upstream)),
+			slog.String("error", a.err.Error()),
+		)
+	}
+
+	logger.LogAttrs(context.Background(), level, "tool call", attrs...)
+}
+
+// redactArgs decodes the raw tool arguments, replacing the values of
+// secret-looking keys at any depth.
+func redactArgs(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return map[string]any{}
+	}
+	var args any
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return redacted
+	}
+	return redactValue(args)
+}
+
+func redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if secretArgPattern.MatchString(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(value)
+			}
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = redactValue(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// resultSize returns the size in bytes of the text content and structured
+// output returned to the client.
+func resultSize(result *mcp.CallToolResult, output any) int {
+	size := 0
+	if result != nil {
+		for _, content := range result.Content {
+			if text, ok := content.(*mcp.TextContent); ok {
+				size += len(text.Text)
+			}
+		}
+	}
+	if output != nil {
+		if b, err := json.Marshal(output); err == nil && string(b) != "null" {
+			size += len(b)
+		}
+	}
+	return size
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+// auditEntries decodes the JSON lines written to an audit log.
+func auditEntries(t *testing.T, log *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(log.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLog(t *testing.T) {
+	var log bytes.Buffer
+	session := newTestSession(t, fake.New(), Options{Audit: slog.New(slog.NewJSONHandler(&log, nil))})
+
+	callTool[any](t, session, "get_dashboard", map[string]any{"dashboard_id": "abc-123-def"})
+	callToolError(t, session, "get_dashboard", map[string]any{"dashboard_id": "nope"})
+
+	entries := auditEntries(t, &log)
+	if len(entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(entries))
+	}
+	args, _ := entries[0]["arguments"].(map[string]any)
+	if entries[0]["tool"] != "get_dashboard" || args["dashboard_id"] != "abc-123-def" {
+		t.Errorf("audit entry = %v, want a get_dashboard call of abc-123-def", entries[0])
+	}
+	if size, _ := entries[0]["result_bytes"].(float64); size == 0 {
+		t.Errorf("audit entry = %v, want the result size", entries[0])
+	}
+	if entries[0]["level"] != "INFO" || entries[1]["level"] != "WARN" || entries[1]["error_class"] != "not_found" {
+		t.Errorf("audit entries = %v, want the failed call logged as a warning with its error class", entries)
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := redactArgs(json.RawMessage(`{
+		"key": "env",
+		"monkey": "banana",
+		"author": "jane",
+		"api_key": "abc",
+		"nested": [{"APP_KEY": "def", "access_token": "ghi", "password": "jkl"}]
+	}`))
+
+	got, err := json.Marshal(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"api_key":"[REDACTED]","author":"jane","key":"env","monkey":"banana","nested":[{"APP_KEY":"[REDACTED]","access_token":"[REDACTED]","password":"[REDACTED]"}]}`
+	if string(got) != want {
+		t.Errorf("redactArgs = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// Downsampling strategies fitting a series into max_data_points.
+const (
+	// DownsampleLTTB keeps the points that best preserve the series' shape,
+	// spikes included (largest triangle three buckets).
+	DownsampleLTTB = "lttb"
+	// DownsampleAvg averages consecutive buckets of points.
+	DownsampleAvg = "avg"
+	// DownsampleMax keeps the highest point of each bucket.
+	DownsampleMax = "max"
+	// DownsampleTail keeps the most recent points, dropping the start of the
+	// range.
+	DownsampleTail = "tail"
+)
+
+// downsampleStrategies lists the strategies, the default first.
+var downsampleStrategies = []string{DownsampleLTTB, DownsampleAvg, DownsampleMax, DownsampleTail}
+
+// parseDownsample validates a downsampling strategy. Empty means the default.
+func parseDownsample(s string) (string, error) {
+	if s == "" {
+		return downsampleStrategies[0], nil
+	}
+	if !slices.Contains(downsampleStrategies, s) {
+		return "", fmt.Errorf("invalid downsample %q: use one of %s", s, strings.Join(downsampleStrategies, ", "))
+	}
+	return s, nil
+}
+
+// downsample reduces points to at most n with the given strategy. Points
+// must be in time order.
+func downsample(points []datadog.MetricPoint, n int, strategy string) []datadog.MetricPoint {
+	if n <= 0 || len(points) <= n {
+		return points
+	}
+	switch strategy {
+	case DownsampleTail:
+		return points[len(points)-n:]
+	case DownsampleAvg, DownsampleMax:
+		return bucket(points, n, strategy)
+	default:
+		return lttb(points, n)
+	}
+}
+
+// bucket splits points into n buckets of consecutive points, and keeps
+// their average, stamped with the start of the bucket, or their highest
+// point.
+func bucket(points []datadog.MetricPoint, n int, strategy string) []datadog.MetricPoint {
+	out := make([]datadog.MetricPoint, n)
+	for i := range n {
+		b := points[i*len(points)/n : (i+1)*len(points)/n]
+		if strategy == DownsampleMax {
+			out[i] = slices.MaxFunc(b, func(p, q datadog.MetricPoint) int {
+				return cmp.Compare(p.Value, q.Value)
+			})
+			continue
+		}
+		var sum float64
+		for _, p := range b {
+			sum += p.Value
+		}
+		out[i] = datadog.MetricPoint{Timestamp: b[0].Timestamp, Value: sum / float64(len(b))}
+	}
+	return out
+}
+
+// lttb keeps n points with the largest triangle three buckets algorithm: the
+// first and last points, and from each bucket in between the point forming
+// the largest triangle with the point kept before it and the average of the
+// next bucket.
+func lttb(points []datadog.MetricPoint, n int) []datadog.MetricPoint {
+	switch n {
+	case 1:
+		return points[len(points)-1:]
+	case 2:
+		return []datadog.MetricPoint{points[0], points[len(points)-1]}
+	}
+
+	// x is a point's time in seconds since the first point.
+	x := func(p datadog.MetricPoint) float64 {
+		return p.Timestamp.Sub(points[0].Timestamp).Seconds()
+	}
+
+	out := make([]datadog.MetricPoint, 0, n)
+	out = append(out, points[0])
+	inner := points[1 : len(points)-1]
+	buckets := n - 2
+	a := points[0]
+	for i := range buckets {
+		b := inner[i*len(inner)/buckets : (i+1)*len(inner)/buckets]
+
+		next := points[len(points)-1:]
+		if i+1 < buckets {
+			next = inner[(i+1)*len(inner)/buckets : (i+2)*len(inner)/buckets]
+		}
+		var cx, cy float64
+		for _, p := range next {
+			cx += x(p)
+			cy += p.Value
+		}
+		cx /= float64(len(next))
+		cy /= float64(len(next))
+
+		best, bestArea := b[0], -1.0
+		for _, p := range b {
+			area := math.Abs((x(a)-cx)*(p.Value-a.Value) - (x(a)-x(p))*(cy-a.Value))
+			if area > bestArea {
+				best, bestArea = p, area
+			}
+		}
+		out = append(out, best)
+		a = best
+	}
+	return append(out, points[len(points)-1])
+}
+
+// resolution returns the average time between points.
+func resolution(points []datadog.MetricPoint) time.Duration {
+	if len(points) < 2 {
+		return 0
+	}
+	return points[len(points)-1].Timestamp.Sub(points[0].Timestamp) / time.Duration(len(points)-1)
+}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+func TestDownsample(t *testing.T) {
+	// One point a minute, with a spike at the third.
+	start := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	var points []datadog.MetricPoint
+	for i, v := range []float64{1, 2, 10, 3, 4, 5, 6, 7} {
+		points = append(points, datadog.MetricPoint{Timestamp: start.Add(time.Duration(i) * time.Minute), Value: v})
+	}
+
+	tests := []struct {
+		strategy string
+		n        int
+		minutes  []int
+		values   []float64
+	}{
+		{DownsampleLTTB, 4, []int{0, 2, 4, 7}, []float64{1, 10, 4, 7}},
+		{DownsampleLTTB, 2, []int{0, 7}, []float64{1, 7}},
+		{DownsampleLTTB, 1, []int{7}, []float64{7}},
+		{DownsampleAvg, 4, []int{0, 2, 4, 6}, []float64{1.5, 6.5, 4.5, 6.5}},
+		{DownsampleMax, 4, []int{1, 2, 5, 7}, []float64{2, 10, 5, 7}},
+		{DownsampleTail, 4, []int{4, 5, 6, 7}, []float64{4, 5, 6, 7}},
+		{DownsampleLTTB, 8, []int{0, 1, 2, 3, 4, 5, 6, 7}, []float64{1, 2, 10, 3, 4, 5, 6, 7}},
+		{DownsampleMax, 0, []int{0, 1, 2, 3, 4, 5, 6, 7}, []float64{1, 2, 10, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		got := downsample(points, tt.n, tt.strategy)
+		var minutes []int
+		var values []float64
+		for _, p := range got {
+			minutes = append(minutes, int(p.Timestamp.Sub(start).Minutes()))
+			values = append(values, p.Value)
+		}
+		if !slices.Equal(minutes, tt.minutes) || !slices.Equal(values, tt.values) {
+			t.Errorf("downsample(%s, %d) = %v at %v, want %v at %v", tt.strategy, tt.n, values, minutes, tt.values, tt.minutes)
+		}
+	}
+
+	if r := resolution(points); r != time.Minute {
+		t.Errorf("resolution = %s, want 1m", r)
+	}
+}
+
+func TestParseDownsample(t *testing.T) {
+	if s, err := parseDownsample(""); s != DownsampleLTTB || err != nil {
+		t.Errorf("parseDownsample(\"\") = %q, %v; want lttb", s, err)
+	}
+	if s, err := parseDownsample("tail"); s != DownsampleTail || err != nil {
+		t.Errorf("parseDownsample(tail) = %q, %v", s, err)
+	}
+	if _, err := parseDownsample("median"); err == nil {
+		t.Error("parseDownsample(median) succeeded, want an error")
+	}
+}
@@ -0,0 +1,128 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// syntaxErrorPattern finds where Datadog's query parser gave up, in messages
+// such as "Rule 'scope_expr' didn't match at 'by {' (line 1, column 30)".
+var syntaxErrorPattern = regexp.MustCompile(`(?:at|near) '([^']+)'`)
+
+// kindHints holds the advice given for each kind of Datadog error, unless the
+// tool has more specific advice in toolHints.
+var kindHints = map[datadog.ErrorKind]string{
+	datadog.ErrorAuth:         "Datadog rejected the API or application key of this org. The server operator should run `datadog-mcp doctor` to check the credentials; retrying will not help.",
+	datadog.ErrorPermission:   "The application key of this org is not allowed to read this data. It needs the matching scope, or its owner the matching permission; try another org or tool in the meantime.",
+	datadog.ErrorRateLimited:  "Datadog's rate limit for this endpoint is used up. Wait for the reset before retrying, and avoid firing many calls in parallel.",
+	datadog.ErrorInvalidQuery: "Datadog rejected the request; fix the arguments before retrying.",
+	datadog.ErrorNotFound:     "The requested object does not exist in this org; check the identifier and the org.",
+	datadog.ErrorUnavailable:  "Datadog is unavailable or failing right now; retry later, or narrow the time range if the query is expensive.",
+}
+
+// metricQueryHint describes the metric query syntax.
+const metricQueryHint = "Metric queries look like `avg:system.cpu.user{env:prod,service:web} by {host}`: an aggregator, a metric name, a scope of tag filters in braces (`{*}` for all), and optional `by {tag}` grouping. Use list_metrics to find metric names."
+
+// toolHints holds tool-specific advice for each kind of Datadog error.
+var toolHints = map[string]map[datadog.ErrorKind]string{
+	"query_metrics": {
+		datadog.ErrorInvalidQuery: metricQueryHint,
+	},
+	"query_timeseries": {
+		datadog.ErrorInvalidQuery: "Each query needs a unique name, and formulas may only refer to those names, e.g. `a / b * 100`. " + metricQueryHint,
+	},
+	"query_apm_stats": {
+		datadog.ErrorInvalidQuery: "Check the service, operation and env names; get_apm_services lists services.",
+	},
+	"query_spans": {
+		datadog.ErrorInvalidQuery: "Span queries use Datadog search syntax, e.g. `service:checkout env:prod @http.status_code:500 -status:ok`. Times look like `now-15m` or RFC3339.",
+	},
+	"get_metric_metadata": {
+		datadog.ErrorNotFound: "No metric has this name in this org; use list_metrics to find metric names.",
+	},
+	"list_metric_tags": {
+		datadog.ErrorNotFound: "No metric has this name in this org, or it reported no data over the past hour; use list_metrics to find metric names.",
+	},
+	"get_dashboard": {
+		datadog.ErrorNotFound: "No dashboard has this ID in this org; use list_dashboards to find dashboard IDs.",
+	},
+}
+
+// hintedError adds remediation advice to a tool error, so the model can
+// correct its call.
+type hintedError struct {
+	err  error
+	hint string
+}
+
+func (e *hintedError) Error() string {
+	return fmt.Sprintf("%v\nHint: %s", e.err, e.hint)
+}
+
+func (e *hintedError) Unwrap() error {
+	return e.err
+}
+
+// withHint returns err with advice on how to avoid it, when the failure is a
+// Datadog API error the caller can act on.
+func withHint(tool string, err error) error {
+	var apiErr *datadog.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	hint, ok := toolHints[tool][apiErr.Kind]
+	if !ok {
+		hint, ok = kindHints[apiErr.Kind]
+	}
+	if !ok {
+		return err
+	}
+
+	if apiErr.Kind == datadog.ErrorInvalidQuery {
+		for _, msg := range apiErr.Messages {
+			if m := syntaxErrorPattern.FindStringSubmatch(msg); m != nil {
+				hint = fmt.Sprintf("Query syntax error near `%s`. %s", m[1], hint)
+				break
+			}
+		}
+	}
+	return &hintedError{err: err, hint: hint}
+}
+
+// errorClass buckets a tool or resource error for auditing and metrics.
+func errorClass(ctx context.Context, err error, upstream []datadog.UpstreamCall) string {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, errResourceNotFound):
+		return "not_found"
+	}
+
+	var apiErr *datadog.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Kind {
+		case datadog.ErrorUnavailable:
+			return "upstream_unavailable"
+		case datadog.ErrorOther:
+			return "upstream_rejected"
+		default:
+			return string(apiErr.Kind)
+		}
+	}
+
+	if n := len(upstream); n > 0 {
+		if last := upstream[n-1]; last.Status >= 500 || last.Error != "" {
+			return "upstream_unavailable"
+		} else if last.Status >= 400 {
+			return "upstream_rejected"
+		}
+	}
+	return "tool"
+}
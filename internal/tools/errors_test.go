@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+func TestWithHint(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		err  error
+		hint string
+	}{
+		{"syntax error", "query_metrics", datadog.NewAPIError("failed to query metrics", http.StatusBadRequest, "Rule 'scope_expr' didn't match at 'by {' (line 1, column 30)"), "Hint: Query syntax error near `by {`. Metric queries look like"},
+		{"tool hint", "get_dashboard", datadog.NewAPIError("failed to get dashboard", http.StatusNotFound), "Hint: No dashboard has this ID in this org"},
+		{"kind hint", "list_metrics", datadog.NewAPIError("failed to list metrics", http.StatusUnauthorized), "Hint: Datadog rejected the API or application key"},
+		{"unclassified", "list_metrics", datadog.NewAPIError("failed to list metrics", http.StatusConflict), ""},
+		{"not an API error", "list_metrics", errors.New("boom"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := withHint(tt.tool, tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("withHint(%v) does not wrap the original error", tt.err)
+			}
+			if tt.hint == "" {
+				if err.Error() != tt.err.Error() {
+					t.Errorf("withHint(%v) = %q, want no hint", tt.err, err)
+				}
+				return
+			}
+			if !strings.Contains(err.Error(), "\n"+tt.hint) {
+				t.Errorf("withHint(%v) = %q, want %q", tt.err, err, tt.hint)
+			}
+		})
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		err      error
+		upstream []datadog.UpstreamCall
+		want     string
+	}{
+		{"timeout", expired, errors.New("slow"), nil, "timeout"},
+		{"canceled", context.Background(), context.Canceled, nil, "canceled"},
+		{"rate limited", context.Background(), datadog.NewAPIError("op", http.StatusTooManyRequests), nil, "rate_limited"},
+		{"unavailable", context.Background(), datadog.NewAPIError("op", http.StatusBadGateway), nil, "upstream_unavailable"},
+		{"rejected", context.Background(), datadog.NewAPIError("op", http.StatusConflict), nil, "upstream_rejected"},
+		{"failed upstream call", context.Background(), errors.New("partial"), []datadog.UpstreamCall{{Status: 503}}, "upstream_unavailable"},
+		{"tool", context.Background(), errors.New("bad argument"), nil, "tool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorClass(tt.ctx, tt.err, tt.upstream); got != tt.want {
+				t.Errorf("errorClass(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -3,53 +3,121 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // GetAPMServicesInput defines the input for the get_apm_services tool.
 type GetAPMServicesInput struct {
 	// No required inputs - lists all services
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org     string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerGetAPMServices(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerGetAPMServices(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "get_apm_services",
 		Description: "List all APM services from the Datadog service catalog with their metadata including team, tier, lifecycle, and contacts.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input GetAPMServicesInput) (*mcp.CallToolResult, *datadog.ListServicesResult, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
 		result, err := client.ListServices(ctx)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		summary := fmt.Sprintf("Found %d services:\n\n", result.Total)
-
-		for _, svc := range result.Services {
-			summary += fmt.Sprintf("Service: %s\n", svc.Name)
-			if svc.Description != "" {
-				summary += fmt.Sprintf("  Description: %s\n", svc.Description)
-			}
-			if svc.Team != "" {
-				summary += fmt.Sprintf("  Team: %s\n", svc.Team)
-			}
-			if svc.Tier != "" {
-				summary += fmt.Sprintf("  Tier: %s\n", svc.Tier)
-			}
-			if svc.Lifecycle != "" {
-				summary += fmt.Sprintf("  Lifecycle: %s\n", svc.Lifecycle)
-			}
-			if len(svc.Languages) > 0 {
-				summary += fmt.Sprintf("  Languages: %v\n", svc.Languages)
-			}
-			summary += "\n"
+		text.Printf("Found %d services:\n\n", result.Total)
+
+		columns := []string{"service", "team", "tier", "lifecycle", "languages", "description"}
+		switch text.Detail() {
+		case render.DetailBrief:
+			columns = columns[:4]
+		case render.DetailFull:
+			columns = append(columns, "tags", "contacts", "links")
 		}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
+		text.List(render.List{
+			Noun:  "services",
+			Count: len(result.Services),
+			Item: func(i int, detail render.Detail) string {
+				svc := result.Services[i]
+				if detail == render.DetailBrief {
+					s := svc.Name
+					for _, v := range []string{svc.Team, svc.Tier, svc.Lifecycle} {
+						if v != "" {
+							s += "  " + v
+						}
+					}
+					return s + "\n"
+				}
+
+				s := fmt.Sprintf("Service: %s\n", svc.Name)
+				if svc.Description != "" {
+					s += fmt.Sprintf("  Description: %s\n", svc.Description)
+				}
+				if svc.Team != "" {
+					s += fmt.Sprintf("  Team: %s\n", svc.Team)
+				}
+				if svc.Tier != "" {
+					s += fmt.Sprintf("  Tier: %s\n", svc.Tier)
+				}
+				if svc.Lifecycle != "" {
+					s += fmt.Sprintf("  Lifecycle: %s\n", svc.Lifecycle)
+				}
+				if len(svc.Languages) > 0 {
+					s += fmt.Sprintf("  Languages: %v\n", svc.Languages)
+				}
+				if detail == render.DetailFull {
+					if len(svc.Tags) > 0 {
+						s += fmt.Sprintf("  Tags: %v\n", svc.Tags)
+					}
+					for _, contact := range svc.Contacts {
+						s += fmt.Sprintf("  Contact (%s): %s\n", contact.Type, contact.Contact)
+					}
+					for _, link := range svc.Links {
+						s += fmt.Sprintf("  Link (%s): %s %s\n", link.Type, link.Name, link.URL)
+					}
+				}
+				return s + "\n"
+			},
+			Columns: columns,
+			Rows: func(i int) [][]string {
+				svc := result.Services[i]
+				contacts := make([]string, len(svc.Contacts))
+				for j, contact := range svc.Contacts {
+					contacts[j] = fmt.Sprintf("%s: %s", contact.Type, contact.Contact)
+				}
+				links := make([]string, len(svc.Links))
+				for j, link := range svc.Links {
+					links[j] = fmt.Sprintf("%s: %s", link.Name, link.URL)
+				}
+				row := []string{svc.Name, svc.Team, svc.Tier, svc.Lifecycle, strings.Join(svc.Languages, ","), svc.Description,
+					strings.Join(svc.Tags, ","), strings.Join(contacts, ", "), strings.Join(links, ", ")}
+				return [][]string{row[:len(columns)]}
 			},
-		}, result, nil
+		})
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
 	})
 }
@@ -0,0 +1,18 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestGetAPMServices(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.ListServicesResult](t, session, "get_apm_services", map[string]any{})
+	assertContains(t, text, "Found 2 services", "Service: checkout", "Team: shop", "Languages: [java]")
+	if out.Total != 2 || out.Services[1].Name != "payments" || len(out.Services[1].Contacts) != 1 {
+		t.Errorf("services = %+v, want checkout and payments with a contact", out.Services)
+	}
+}
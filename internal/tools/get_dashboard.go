@@ -3,76 +3,135 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // GetDashboardInput defines the input for the get_dashboard tool.
 type GetDashboardInput struct {
 	DashboardID string `json:"dashboard_id" jsonschema:"The dashboard ID to retrieve"`
+	NoCache     bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org         string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerGetDashboard(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerGetDashboard(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "get_dashboard",
 		Description: "Get detailed information about a specific dashboard including its configuration, widgets, and template variables.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input GetDashboardInput) (*mcp.CallToolResult, *datadog.Dashboard, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
 		result, err := client.GetDashboard(ctx, input.DashboardID)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		summary := fmt.Sprintf("Dashboard: %s\n", result.Title)
-		summary += fmt.Sprintf("ID: %s\n", result.ID)
+		text.Printf("Dashboard: %s\n", result.Title)
+		text.Printf("ID: %s\n", result.ID)
 		if result.Description != "" {
-			summary += fmt.Sprintf("Description: %s\n", result.Description)
+			text.Printf("Description: %s\n", result.Description)
 		}
-		summary += fmt.Sprintf("Layout Type: %s\n", result.LayoutType)
+		text.Printf("Layout Type: %s\n", result.LayoutType)
 		if result.URL != "" {
-			summary += fmt.Sprintf("URL: %s\n", result.URL)
+			text.Printf("URL: %s\n", result.URL)
 		}
 		if result.AuthorHandle != "" {
-			summary += fmt.Sprintf("Author: %s", result.AuthorHandle)
+			text.Printf("Author: %s", result.AuthorHandle)
 			if result.AuthorName != "" {
-				summary += fmt.Sprintf(" (%s)", result.AuthorName)
+				text.Printf(" (%s)", result.AuthorName)
 			}
-			summary += "\n"
+			text.Printf("\n")
 		}
 		if !result.CreatedAt.IsZero() {
-			summary += fmt.Sprintf("Created: %s\n", result.CreatedAt.Format("2006-01-02 15:04:05"))
+			text.Printf("Created: %s\n", result.CreatedAt.Format("2006-01-02 15:04:05"))
 		}
 		if !result.ModifiedAt.IsZero() {
-			summary += fmt.Sprintf("Modified: %s\n", result.ModifiedAt.Format("2006-01-02 15:04:05"))
+			text.Printf("Modified: %s\n", result.ModifiedAt.Format("2006-01-02 15:04:05"))
 		}
 		if result.IsReadOnly {
-			summary += "Read Only: Yes\n"
+			text.Printf("Read Only: Yes\n")
 		}
 		if len(result.Tags) > 0 {
-			summary += fmt.Sprintf("Tags: %v\n", result.Tags)
+			text.Printf("Tags: %v\n", result.Tags)
 		}
 
-		summary += fmt.Sprintf("\nWidgets: %d\n", result.WidgetCount)
-
 		if len(result.TemplateVariables) > 0 {
-			summary += "\nTemplate Variables:\n"
-			for _, tv := range result.TemplateVariables {
-				summary += fmt.Sprintf("  - %s", tv.Name)
-				if tv.Prefix != "" {
-					summary += fmt.Sprintf(" (prefix: %s)", tv.Prefix)
-				}
-				if tv.Default != "" {
-					summary += fmt.Sprintf(" [default: %s]", tv.Default)
-				}
-				summary += "\n"
+			columns := []string{"name", "prefix", "default"}
+			if text.Detail() == render.DetailFull {
+				columns = append(columns, "values")
 			}
+			text.Printf("\nTemplate Variables:\n")
+			text.List(render.List{
+				Noun:  "template variables",
+				Count: len(result.TemplateVariables),
+				Item: func(i int, detail render.Detail) string {
+					tv := result.TemplateVariables[i]
+					s := fmt.Sprintf("  - %s", tv.Name)
+					if tv.Prefix != "" {
+						s += fmt.Sprintf(" (prefix: %s)", tv.Prefix)
+					}
+					if tv.Default != "" {
+						s += fmt.Sprintf(" [default: %s]", tv.Default)
+					}
+					if detail == render.DetailFull && len(tv.AvailableValues) > 0 {
+						s += fmt.Sprintf(" values: %s", strings.Join(tv.AvailableValues, ", "))
+					}
+					return s + "\n"
+				},
+				Columns: columns,
+				Rows: func(i int) [][]string {
+					tv := result.TemplateVariables[i]
+					row := []string{tv.Name, tv.Prefix, tv.Default, strings.Join(tv.AvailableValues, ",")}
+					return [][]string{row[:len(columns)]}
+				},
+			})
+		}
+
+		text.Printf("\nWidgets: %d\n", result.WidgetCount)
+		if text.Detail() == render.DetailFull {
+			text.List(render.List{
+				Noun:  "widgets",
+				Count: len(result.Widgets),
+				Item: func(i int, detail render.Detail) string {
+					def := result.Widgets[i].Definition
+					s := fmt.Sprintf("  - %v", def["type"])
+					if title, _ := def["title"].(string); title != "" {
+						s += ": " + title
+					}
+					return s + "\n"
+				},
+				Columns: []string{"type", "title"},
+				Rows: func(i int) [][]string {
+					def := result.Widgets[i].Definition
+					title, _ := def["title"].(string)
+					return [][]string{{fmt.Sprint(def["type"]), title}}
+				},
+			})
+		} else if len(result.Widgets) > 0 {
+			text.Note("Widget definitions are not listed at detail=%s; set detail=full or see the structured output.", text.Detail())
 		}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
-		}, result, nil
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
 	})
 }
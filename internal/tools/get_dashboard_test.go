@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestGetDashboard(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.Dashboard](t, session, "get_dashboard", map[string]any{"dashboard_id": "abc-123-def"})
+	assertContains(t, text, "Dashboard: Checkout Overview", "ID: abc-123-def", "env (prefix: env) [default: production]", "Widgets: 1")
+	if out.WidgetCount != 1 || len(out.Widgets) != 1 {
+		t.Errorf("got %d widgets (count %d), want 1", len(out.Widgets), out.WidgetCount)
+	}
+
+	text = callToolError(t, session, "get_dashboard", map[string]any{"dashboard_id": "nope"})
+	assertContains(t, text, "404 Not Found", "Hint:", "list_dashboards")
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// GetMetricMetadataInput defines the input for the get_metric_metadata tool.
+type GetMetricMetadataInput struct {
+	Metric  string `json:"metric" jsonschema:"The metric name, e.g. system.cpu.user"`
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org     string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
+}
+
+func registerGetMetricMetadata(r *registry) {
+	addTool(r, &mcp.Tool{
+		Name:        "get_metric_metadata",
+		Description: "Get what a metric measures: its type (gauge, count, rate or distribution), unit and per unit, description, integration and submission interval.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input GetMetricMetadataInput) (*mcp.CallToolResult, *datadog.MetricMetadata, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
+		result, err := client.GetMetricMetadata(ctx, input.Metric)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		text.Printf("Metric: %s\n", result.Metric)
+		if result.ShortName != "" {
+			text.Printf("Short Name: %s\n", result.ShortName)
+		}
+		if result.Type != "" {
+			text.Printf("Type: %s\n", result.Type)
+		}
+		if result.Unit != "" {
+			text.Printf("Unit: %s", result.Unit)
+			if result.PerUnit != "" {
+				text.Printf(" per %s", result.PerUnit)
+			}
+			text.Printf("\n")
+		}
+		if result.Description != "" {
+			text.Printf("Description: %s\n", result.Description)
+		}
+		if result.Integration != "" {
+			text.Printf("Integration: %s\n", result.Integration)
+		}
+		if result.StatsdInterval > 0 {
+			text.Printf("Submission Interval: %s\n", formatDuration(time.Duration(result.StatsdInterval)*time.Second))
+		}
+		if result.Type == "" && result.Unit == "" && result.Description == "" {
+			text.Note("No metadata is set for this metric; Datadog may still report data for it.")
+		}
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
+	})
+}
@@ -0,0 +1,21 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestGetMetricMetadata(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.MetricMetadata](t, session, "get_metric_metadata", map[string]any{"metric": "system.mem.used"})
+	assertContains(t, text, "Metric: system.mem.used", "Type: gauge", "Unit: byte", "Integration: system", "Submission Interval: 15s")
+	if out.Type != "gauge" || out.Unit != "byte" || out.StatsdInterval != 15 {
+		t.Errorf("metadata = %+v, want a gauge in bytes every 15s", out)
+	}
+
+	text = callToolError(t, session, "get_metric_metadata", map[string]any{"metric": "system.nope"})
+	assertContains(t, text, "404 Not Found", "Hint:", "list_metrics")
+}
@@ -3,60 +3,121 @@ package tools
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // ListDashboardsInput defines the input for the list_dashboards tool.
 type ListDashboardsInput struct {
-	FilterShared  bool  `json:"filter_shared,omitempty" jsonschema:"Filter to only shared dashboards"`
-	FilterDeleted bool  `json:"filter_deleted,omitempty" jsonschema:"Include deleted dashboards"`
-	Limit         int64 `json:"limit,omitempty" jsonschema:"Maximum number of dashboards to return (1-1000). Defaults to 100"`
-	Start         int64 `json:"start,omitempty" jsonschema:"Starting position for pagination (0-based offset). Defaults to 0"`
+	FilterShared  bool   `json:"filter_shared,omitempty" jsonschema:"Filter to only shared dashboards"`
+	FilterDeleted bool   `json:"filter_deleted,omitempty" jsonschema:"Include deleted dashboards"`
+	Limit         int64  `json:"limit,omitempty" jsonschema:"Maximum number of dashboards to return (1-1000). Defaults to 100"`
+	Start         int64  `json:"start,omitempty" jsonschema:"Starting position for pagination (0-based offset). Defaults to 0"`
+	NoCache       bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org           string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerListDashboards(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerListDashboards(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "list_dashboards",
 		Description: "List all dashboards in your Datadog account. Returns dashboard titles, IDs, layout types, and metadata.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ListDashboardsInput) (*mcp.CallToolResult, *datadog.ListDashboardsResult, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
 		result, err := client.ListDashboards(ctx, input.FilterShared, input.FilterDeleted, input.Limit, input.Start)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		summary := fmt.Sprintf("Found %d dashboards (showing %d-%d of %d total):\n\n",
-			len(result.Dashboards), result.Start+1, result.Start+int64(len(result.Dashboards)), result.Total)
-
-		for i, d := range result.Dashboards {
-			if i >= 50 {
-				summary += fmt.Sprintf("\n... and %d more dashboards in this page", len(result.Dashboards)-50)
-				break
-			}
-			summary += fmt.Sprintf("[%s] %s\n", d.ID, d.Title)
-			if d.Description != "" {
-				summary += fmt.Sprintf("  Description: %s\n", d.Description)
-			}
-			summary += fmt.Sprintf("  Layout: %s\n", d.LayoutType)
-			if d.AuthorHandle != "" {
-				summary += fmt.Sprintf("  Author: %s\n", d.AuthorHandle)
-			}
-			if !d.ModifiedAt.IsZero() {
-				summary += fmt.Sprintf("  Modified: %s\n", d.ModifiedAt.Format("2006-01-02 15:04:05"))
-			}
-			summary += "\n"
+		// Datadog does not count dashboards, so the total is only known
+		// on a last page that is not past the end.
+		start, end := int(result.Start), int(result.Start)+len(result.Dashboards)
+		total := -1
+		if !result.HasMore && (end > start || start == 0) {
+			total = end
 		}
+		text.Printf("Found %d dashboards", len(result.Dashboards))
+		text.Printf("%s", pageHeading("dashboards", start, end, total))
 
-		if result.HasMore {
-			summary += fmt.Sprintf("\nMore results available. Use start=%d to get the next page.", result.Start+int64(len(result.Dashboards)))
+		columns := []string{"id", "title", "layout", "author", "modified", "description"}
+		switch text.Detail() {
+		case render.DetailBrief:
+			columns = columns[:2]
+		case render.DetailFull:
+			columns = append(columns, "url", "created", "read_only")
 		}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
+		text.List(render.List{
+			Noun:  "dashboards",
+			Count: len(result.Dashboards),
+			Max:   r.limits.MaxDashboards,
+			Item: func(i int, detail render.Detail) string {
+				d := result.Dashboards[i]
+				if detail == render.DetailBrief {
+					return fmt.Sprintf("[%s] %s\n", d.ID, d.Title)
+				}
+
+				s := fmt.Sprintf("[%s] %s\n", d.ID, d.Title)
+				if d.Description != "" {
+					s += fmt.Sprintf("  Description: %s\n", d.Description)
+				}
+				s += fmt.Sprintf("  Layout: %s\n", d.LayoutType)
+				if d.AuthorHandle != "" {
+					s += fmt.Sprintf("  Author: %s\n", d.AuthorHandle)
+				}
+				if !d.ModifiedAt.IsZero() {
+					s += fmt.Sprintf("  Modified: %s\n", d.ModifiedAt.Format("2006-01-02 15:04:05"))
+				}
+				if detail == render.DetailFull {
+					if d.URL != "" {
+						s += fmt.Sprintf("  URL: %s\n", d.URL)
+					}
+					if !d.CreatedAt.IsZero() {
+						s += fmt.Sprintf("  Created: %s\n", d.CreatedAt.Format("2006-01-02 15:04:05"))
+					}
+					if d.IsReadOnly {
+						s += "  Read Only: Yes\n"
+					}
+				}
+				return s + "\n"
+			},
+			Columns: columns,
+			Rows: func(i int) [][]string {
+				d := result.Dashboards[i]
+				row := []string{d.ID, d.Title, d.LayoutType, d.AuthorHandle, formatDate(d.ModifiedAt), d.Description,
+					d.URL, formatDate(d.CreatedAt), strconv.FormatBool(d.IsReadOnly)}
+				return [][]string{row[:len(columns)]}
+			},
+			Retrieve: func(first int) string {
+				return fmt.Sprintf("see the structured output, or list them with start=%d", result.Start+int64(first))
 			},
-		}, result, nil
+		})
+
+		if result.HasMore {
+			text.Printf("\nMore results available. Use start=%d to get the next page.", result.Start+int64(len(result.Dashboards)))
+		}
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
 	})
 }
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestListDashboards(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.ListDashboardsResult](t, session, "list_dashboards", map[string]any{})
+	assertContains(t, text, "Found 2 dashboards\nShowing 1-2 of 2:", "[abc-123-def] Checkout Overview", "Author: shop@example.com", "[ghi-456-jkl] Host Health")
+	if len(out.Dashboards) != 2 || out.HasMore {
+		t.Errorf("got %d dashboards (has more %v), want 2", len(out.Dashboards), out.HasMore)
+	}
+
+	text, out = callTool[datadog.ListDashboardsResult](t, session, "list_dashboards", map[string]any{"limit": 1})
+	assertContains(t, text, "Showing 1-1:", "Use start=1")
+	if len(out.Dashboards) != 1 || !out.HasMore {
+		t.Errorf("got %d dashboards (has more %v), want 1 and more", len(out.Dashboards), out.HasMore)
+	}
+
+	text, _ = callTool[datadog.ListDashboardsResult](t, session, "list_dashboards", map[string]any{"start": 1})
+	assertContains(t, text, "Showing 2-2 of 2:", "[ghi-456-jkl] Host Health")
+}
+
+func TestListDashboardsEmptyPage(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, _ := callTool[datadog.ListDashboardsResult](t, session, "list_dashboards", map[string]any{"start": 5})
+	assertContains(t, text, "Found 0 dashboards\nOffset 5 is past the last of the dashboards.")
+
+	backend := fake.New()
+	backend.Dashboards = nil
+	session = newTestSession(t, backend, Options{})
+	text, _ = callTool[datadog.ListDashboardsResult](t, session, "list_dashboards", map[string]any{})
+	assertContains(t, text, "No dashboards match.")
+}
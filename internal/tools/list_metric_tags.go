@@ -0,0 +1,235 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
+)
+
+// tagValueSample is the number of values shown for each tag key.
+const tagValueSample = 5
+
+// ListMetricTagsInput defines the input for the list_metric_tags tool.
+type ListMetricTagsInput struct {
+	Metric  string `json:"metric" jsonschema:"The metric name, e.g. system.cpu.user"`
+	Key     string `json:"key,omitempty" jsonschema:"List the values of this tag key, e.g. host. Without it, the metric's tag keys are listed with their number of distinct values"`
+	Prefix  string `json:"prefix,omitempty" jsonschema:"Only list tag keys, or with key the values, starting with this prefix"`
+	Limit   int    `json:"limit,omitempty" jsonschema:"Maximum number of keys or values to return per page. Defaults to 100 unless configured otherwise"`
+	Offset  int    `json:"offset,omitempty" jsonschema:"Number of keys or values to skip for pagination. Defaults to 0"`
+	NoCache bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org     string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
+}
+
+// MetricTagsResult lists the tag keys of a metric, or the values of one of
+// its keys.
+type MetricTagsResult struct {
+	Metric  string   `json:"metric"`
+	Key     string   `json:"key,omitempty"`
+	Keys    []TagKey `json:"keys,omitempty"`
+	Values  []string `json:"values,omitempty"`
+	Total   int      `json:"total"`
+	Offset  int      `json:"offset"`
+	HasMore bool     `json:"has_more"`
+}
+
+// TagKey describes a tag key of a metric.
+type TagKey struct {
+	Key            string `json:"key"`
+	DistinctValues int    `json:"distinct_values"`
+	// Values samples the key's values.
+	Values []string `json:"values,omitempty"`
+	// Active means the key is actively queried, on dashboards, monitors or
+	// through the API.
+	Active bool `json:"active,omitempty"`
+}
+
+func registerListMetricTags(r *registry) {
+	addTool(r, &mcp.Tool{
+		Name:        "list_metric_tags",
+		Description: "List the tag keys a metric was reported with over the past hour, with their number of distinct values, or the values of one key. Use it to build scopes such as {env:production} and groupings such as by {host} for metric queries.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input ListMetricTagsInput) (*mcp.CallToolResult, *MetricTagsResult, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
+		tags, err := client.ListMetricTags(ctx, input.Metric)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Group values by key. Tags without a colon are keys without values.
+		values := make(map[string][]string)
+		for _, tag := range tags.Tags {
+			key, value, _ := strings.Cut(tag, ":")
+			if _, ok := values[key]; !ok {
+				values[key] = make([]string, 0)
+			}
+			if value != "" && !slices.Contains(values[key], value) {
+				values[key] = append(values[key], value)
+			}
+		}
+
+		limit := input.Limit
+		if limit <= 0 {
+			limit = r.limits.MaxMetrics
+		}
+		offset := max(input.Offset, 0)
+		result := &MetricTagsResult{Metric: input.Metric, Key: input.Key, Offset: offset}
+
+		if input.Key != "" {
+			all, ok := values[input.Key]
+			if !ok {
+				keys := slices.Sorted(maps.Keys(values))
+				return nil, nil, fmt.Errorf("metric %s has no tag key %q over the past hour; its keys are: %s", input.Metric, input.Key, strings.Join(keys, ", "))
+			}
+			matched := make([]string, 0)
+			for _, v := range all {
+				if strings.HasPrefix(v, input.Prefix) {
+					matched = append(matched, v)
+				}
+			}
+			slices.Sort(matched)
+
+			start := min(offset, len(matched))
+			end := min(start+limit, len(matched))
+			result.Values = matched[start:end]
+			result.Total = len(matched)
+			result.HasMore = end < len(matched)
+
+			text.Printf("Tag key %s of %s has %d values over the past hour", input.Key, input.Metric, len(all))
+			if input.Prefix != "" {
+				text.Printf(", %d starting with %q", len(matched), input.Prefix)
+			}
+			text.Printf("%s", pageHeading("values", offset, end, len(matched)))
+
+			text.List(render.List{
+				Noun:  "values",
+				Count: len(result.Values),
+				Item: func(i int, detail render.Detail) string {
+					return input.Key + ":" + result.Values[i] + "\n"
+				},
+				Columns: []string{"tag"},
+				Rows: func(i int) [][]string {
+					return [][]string{{input.Key + ":" + result.Values[i]}}
+				},
+				Retrieve: func(first int) string {
+					return fmt.Sprintf("see the structured output, or list them with offset=%d", start+first)
+				},
+			})
+		} else {
+			keys := make([]TagKey, 0, len(values))
+			for key, vs := range values {
+				if !strings.HasPrefix(key, input.Prefix) {
+					continue
+				}
+				slices.Sort(vs)
+				keys = append(keys, TagKey{
+					Key:            key,
+					DistinctValues: len(vs),
+					Values:         vs[:min(tagValueSample, len(vs))],
+					Active:         slices.Contains(tags.ActiveTags, key),
+				})
+			}
+			// Actively queried keys are the likeliest to be useful.
+			slices.SortFunc(keys, func(a, b TagKey) int {
+				if a.Active != b.Active {
+					if a.Active {
+						return -1
+					}
+					return 1
+				}
+				return cmp.Compare(a.Key, b.Key)
+			})
+
+			start := min(offset, len(keys))
+			end := min(start+limit, len(keys))
+			result.Keys = keys[start:end]
+			result.Total = len(keys)
+			result.HasMore = end < len(keys)
+
+			text.Printf("Metric %s has %d tag keys over the past hour", input.Metric, len(values))
+			if input.Prefix != "" {
+				text.Printf(", %d starting with %q", len(keys), input.Prefix)
+			}
+			text.Printf("%s", pageHeading("tag keys", offset, end, len(keys)))
+
+			text.List(render.List{
+				Noun:  "tag keys",
+				Count: len(result.Keys),
+				Item: func(i int, detail render.Detail) string {
+					k := result.Keys[i]
+					s := fmt.Sprintf("%s (%d values", k.Key, k.DistinctValues)
+					if k.Active {
+						s += ", actively queried"
+					}
+					s += ")"
+					switch {
+					case detail == render.DetailBrief || k.DistinctValues == 0:
+					case detail == render.DetailFull:
+						s += ": " + strings.Join(values[k.Key], ", ")
+					case k.DistinctValues > len(k.Values):
+						s += fmt.Sprintf(": %s, ... (list them with key=%s)", strings.Join(k.Values, ", "), k.Key)
+					default:
+						s += ": " + strings.Join(k.Values, ", ")
+					}
+					return s + "\n"
+				},
+				Columns: []string{"key", "values", "active", "sample"},
+				Rows: func(i int) [][]string {
+					k := result.Keys[i]
+					return [][]string{{k.Key, strconv.Itoa(k.DistinctValues), strconv.FormatBool(k.Active), strings.Join(k.Values, ",")}}
+				},
+				Retrieve: func(first int) string {
+					return fmt.Sprintf("see the structured output, or list them with offset=%d", start+first)
+				},
+			})
+		}
+
+		if result.HasMore {
+			text.Printf("\nMore results available. Use offset=%d to get the next page.", offset+len(result.Keys)+len(result.Values))
+		}
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
+	})
+}
+
+// pageHeading introduces the page of total items from offset to end, or says
+// why it is empty. A negative total means the total is unknown, as when
+// Datadog pages results without counting them.
+func pageHeading(noun string, offset, end, total int) string {
+	switch {
+	case total == 0:
+		return fmt.Sprintf("\nNo %s match.", noun)
+	case total < 0 && offset >= end:
+		return fmt.Sprintf("\nOffset %d is past the last of the %s.", offset, noun)
+	case total < 0:
+		return fmt.Sprintf("\nShowing %d-%d:\n\n", offset+1, end)
+	case offset >= total:
+		return fmt.Sprintf("\nOffset %d is past the last of the %d %s.", offset, total, noun)
+	}
+	return fmt.Sprintf("\nShowing %d-%d of %d:\n\n", offset+1, end, total)
+}
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestListMetricTagsKeys(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user"})
+	assertContains(t, text,
+		"Metric system.cpu.user has 3 tag keys",
+		"host (2 values, actively queried): web-1, web-2",
+		"env (1 values): production",
+	)
+	var keys []string
+	for _, k := range out.Keys {
+		keys = append(keys, k.Key)
+	}
+	// Actively queried keys come first.
+	if want := []string{"host", "availability-zone", "env"}; !slices.Equal(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if !out.Keys[0].Active || out.Keys[1].DistinctValues != 2 {
+		t.Errorf("keys = %+v, want host active and 2 availability zones", out.Keys)
+	}
+
+	_, out = callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "prefix": "av"})
+	if len(out.Keys) != 1 || out.Keys[0].Key != "availability-zone" {
+		t.Errorf("keys with prefix av = %+v, want availability-zone", out.Keys)
+	}
+
+	text, out = callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "prefix": "team"})
+	assertContains(t, text, "0 starting with \"team\"", "No tag keys match.")
+	if len(out.Keys) != 0 || out.Total != 0 {
+		t.Errorf("keys with prefix team = %+v, want none", out.Keys)
+	}
+}
+
+func TestListMetricTagsValues(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "key": "host", "limit": 1})
+	assertContains(t, text, "Tag key host of system.cpu.user has 2 values", "host:web-1", "Use offset=1")
+	if !slices.Equal(out.Values, []string{"web-1"}) || out.Total != 2 || !out.HasMore {
+		t.Errorf("values = %v of %d (has more %v), want web-1 of 2", out.Values, out.Total, out.HasMore)
+	}
+
+	_, out = callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "key": "host", "offset": 1})
+	if !slices.Equal(out.Values, []string{"web-2"}) || out.HasMore {
+		t.Errorf("second page = %v (has more %v), want web-2", out.Values, out.HasMore)
+	}
+
+	text, out = callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "key": "host", "offset": 5})
+	assertContains(t, text, "Offset 5 is past the last of the 2 values.")
+	if len(out.Values) != 0 || out.HasMore {
+		t.Errorf("page past the end = %v (has more %v), want none", out.Values, out.HasMore)
+	}
+
+	text, _ = callTool[MetricTagsResult](t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "key": "host", "prefix": "db-"})
+	assertContains(t, text, "No values match.")
+
+	text = callToolError(t, session, "list_metric_tags", map[string]any{"metric": "system.cpu.user", "key": "team"})
+	assertContains(t, text, `no tag key "team"`, "availability-zone, env, host")
+
+	text = callToolError(t, session, "list_metric_tags", map[string]any{"metric": "system.nope"})
+	assertContains(t, text, "404 Not Found", "list_metrics")
+}
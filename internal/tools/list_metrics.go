@@ -9,6 +9,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // ListMetricsInput defines the input for the list_metrics tool.
@@ -16,16 +17,33 @@ type ListMetricsInput struct {
 	TagFilter string `json:"tag_filter,omitempty" jsonschema:"Filter metrics by tag, e.g. env:production"`
 	Host      string `json:"host,omitempty" jsonschema:"Filter metrics by host name"`
 	Prefix    string `json:"prefix,omitempty" jsonschema:"Filter metrics by name prefix (client-side filtering)"`
-	Limit     int    `json:"limit,omitempty" jsonschema:"Maximum number of metrics to return per page. Defaults to 100"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"Maximum number of metrics to return per page. Defaults to 100 unless configured otherwise"`
 	Offset    int    `json:"offset,omitempty" jsonschema:"Number of metrics to skip for pagination. Defaults to 0"`
+	NoCache   bool   `json:"no_cache,omitempty" jsonschema:"Bypass the response cache and fetch fresh data from Datadog"`
+	Org       string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerListMetrics(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerListMetrics(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "list_metrics",
 		Description: "List available metrics in Datadog. Can filter by tag, host, or name prefix.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input ListMetricsInput) (*mcp.CallToolResult, *datadog.ListMetricsResult, error) {
-		from := time.Now().Add(-24 * time.Hour)
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if input.NoCache {
+			ctx = datadog.WithoutCache(ctx)
+		}
+
+		from := time.Now().Add(-r.opts.lookback("list_metrics"))
 
 		result, err := client.ListMetrics(ctx, from, input.Host, input.TagFilter)
 		if err != nil {
@@ -48,7 +66,7 @@ func registerListMetrics(server *mcp.Server, client *datadog.Client) {
 		// Apply pagination (client-side since Datadog API doesn't support it for list metrics)
 		limit := input.Limit
 		if limit <= 0 {
-			limit = 100
+			limit = r.limits.MaxMetrics
 		}
 		offset := input.Offset
 		if offset < 0 {
@@ -68,24 +86,35 @@ func registerListMetrics(server *mcp.Server, client *datadog.Client) {
 		paginatedMetrics := result.Metrics[start:end]
 		hasMore := end < len(result.Metrics)
 
-		summary := fmt.Sprintf("Found %d metrics total", totalMetrics)
+		text.Printf("Found %d metrics active since %s", totalMetrics, from.UTC().Format(time.RFC3339))
 		if input.TagFilter != "" {
-			summary += fmt.Sprintf(" (tag filter: %s)", input.TagFilter)
+			text.Printf(" (tag filter: %s)", input.TagFilter)
 		}
 		if input.Host != "" {
-			summary += fmt.Sprintf(" (host: %s)", input.Host)
+			text.Printf(" (host: %s)", input.Host)
 		}
 		if input.Prefix != "" {
-			summary += fmt.Sprintf(" (prefix: %s)", input.Prefix)
+			text.Printf(" (prefix: %s)", input.Prefix)
 		}
-		summary += fmt.Sprintf("\nShowing %d-%d of %d:\n\n", start+1, start+len(paginatedMetrics), totalMetrics)
+		text.Printf("%s", pageHeading("metrics", offset, end, totalMetrics))
 
-		for _, metric := range paginatedMetrics {
-			summary += metric + "\n"
-		}
+		text.List(render.List{
+			Noun:  "metrics",
+			Count: len(paginatedMetrics),
+			Item: func(i int, detail render.Detail) string {
+				return paginatedMetrics[i] + "\n"
+			},
+			Columns: []string{"metric"},
+			Rows: func(i int) [][]string {
+				return [][]string{{paginatedMetrics[i]}}
+			},
+			Retrieve: func(first int) string {
+				return fmt.Sprintf("see the structured output, or list them with offset=%d", start+first)
+			},
+		})
 
 		if hasMore {
-			summary += fmt.Sprintf("\nMore results available. Use offset=%d to get the next page.", end)
+			text.Printf("\nMore results available. Use offset=%d to get the next page.", end)
 		}
 
 		// Update result with paginated metrics for structured output
@@ -94,10 +123,10 @@ func registerListMetrics(server *mcp.Server, client *datadog.Client) {
 		result.Offset = offset
 		result.HasMore = hasMore
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
-		}, result, nil
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
 	})
 }
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestListMetrics(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.ListMetricsResult](t, session, "list_metrics", map[string]any{"prefix": "system."})
+	assertContains(t, text, "Found 2 metrics", "(prefix: system.)", "system.cpu.user\nsystem.mem.used")
+	if want := []string{"system.cpu.user", "system.mem.used"}; !slices.Equal(out.Metrics, want) {
+		t.Errorf("metrics = %v, want %v", out.Metrics, want)
+	}
+
+	text, out = callTool[datadog.ListMetricsResult](t, session, "list_metrics", map[string]any{"limit": 2, "offset": 1})
+	assertContains(t, text, "Showing 2-3 of 5:", "Use offset=3")
+	if out.Total != 5 || !out.HasMore || !slices.Equal(out.Metrics, []string{"system.mem.used", "trace.checkout.duration"}) {
+		t.Errorf("page = %v of %d (has more %v), want system.mem.used and trace.checkout.duration of 5", out.Metrics, out.Total, out.HasMore)
+	}
+}
+
+func TestListMetricsEmptyPage(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, _ := callTool[datadog.ListMetricsResult](t, session, "list_metrics", map[string]any{"offset": 7})
+	assertContains(t, text, "Offset 7 is past the last of the 5 metrics.")
+
+	text, _ = callTool[datadog.ListMetricsResult](t, session, "list_metrics", map[string]any{"prefix": "nope."})
+	assertContains(t, text, "No metrics match.")
+}
+
+func TestListMetricsHost(t *testing.T) {
+	backend := fake.New()
+	session := newTestSession(t, backend, Options{})
+
+	_, out := callTool[datadog.ListMetricsResult](t, session, "list_metrics", map[string]any{"host": "web-1"})
+	if want := []string{"system.cpu.user", "system.mem.used"}; !slices.Equal(out.Metrics, want) {
+		t.Errorf("metrics of host web-1 = %v, want %v", out.Metrics, want)
+	}
+	if calls := backend.Calls(); !slices.Equal(calls, []string{"ListMetrics"}) {
+		t.Errorf("calls = %v, want [ListMetrics]", calls)
+	}
+}
@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/render"
+)
+
+// OutputOptions holds the arguments shaping a tool's text summary. Tool
+// inputs embed it, so every tool accepts them.
+type OutputOptions struct {
+	OutputFormat   string `json:"output_format,omitempty" jsonschema:"Format of the text summary: text (compact), markdown (lists as tables), csv (lists as CSV, e.g. metric series and spans) or json (the structured output as JSON). Defaults to text"`
+	Detail         string `json:"detail,omitempty" jsonschema:"How much the text summary spells out: brief (one line per item), normal or full (every attribute). Defaults to normal"`
+	MaxOutputChars int    `json:"max_output_chars,omitempty" jsonschema:"Character budget of the text summary, about 4 characters per token. Defaults to 8000 unless configured otherwise"`
+}
+
+// formatDate formats t for a table cell, leaving the zero time empty.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// formatDuration formats d to the second, without zero minutes and seconds,
+// e.g. 1h rather than 1h0m0s.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.String()
+	}
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// text returns a summary builder honouring the caller's output options.
+func (r *registry) text(opts OutputOptions) (*render.Text, error) {
+	format, err := render.ParseFormat(opts.OutputFormat)
+	if err != nil {
+		return nil, err
+	}
+	detail, err := render.ParseDetail(opts.Detail)
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.MaxOutputChars
+	if limit <= 0 {
+		limit = r.limits.MaxOutputChars
+	}
+	return render.NewText(limit, detail, format), nil
+}
+
+// textResult returns a tool result carrying the summary built in text. In the
+// JSON format the summary is output itself, in full: cutting it to the
+// budget would leave invalid JSON.
+func textResult(text *render.Text, output any) (*mcp.CallToolResult, error) {
+	if text.Format() == render.FormatJSON {
+		data, err := json.Marshal(output)
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{
+				&mcp.TextContent{Text: string(data)},
+			},
+		}, nil
+	}
+
+	var content []mcp.Content
+	for _, part := range text.Parts() {
+		content = append(content, &mcp.TextContent{Text: part})
+	}
+	return &mcp.CallToolResult{Content: content}, nil
+}
@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Policy decides which tools are exposed to MCP clients.
+type Policy struct {
+	// Enabled lists name patterns (path.Match globs, e.g. "query_*") of the
+	// tools to expose. Empty means all tools.
+	Enabled []string
+	// Disabled lists name patterns of tools to hide. It takes precedence over
+	// Enabled.
+	Disabled []string
+	// ReadOnly refuses to expose tools that are not annotated as read-only,
+	// i.e. any tool that may modify Datadog.
+	ReadOnly bool
+}
+
+// Registration records whether a tool was exposed and, if not, why.
+type Registration struct {
+	Name    string
+	Exposed bool
+	Reason  string
+}
+
+// check reports whether the policy allows tool and, if not, why.
+func (p Policy) check(tool *mcp.Tool) (bool, string) {
+	if p.ReadOnly && (tool.Annotations == nil || !tool.Annotations.ReadOnlyHint) {
+		return false, "may modify Datadog and the server is read-only"
+	}
+	if pattern, ok := matchAny(p.Disabled, tool.Name); ok {
+		return false, fmt.Sprintf("disabled by pattern %q", pattern)
+	}
+	if len(p.Enabled) > 0 {
+		if _, ok := matchAny(p.Enabled, tool.Name); !ok {
+			return false, "not in the enabled tools"
+		}
+	}
+	return true, ""
+}
+
+// matchAny returns the first of patterns matching name.
+func matchAny(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// CheckPatterns returns an error for any entry of patterns that is malformed
+// or matches no tool, which usually means a typo.
+func CheckPatterns(patterns []string) error {
+	known := Names()
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+		if !slices.ContainsFunc(known, func(name string) bool {
+			_, ok := matchAny([]string{pattern}, name)
+			return ok
+		}) {
+			return fmt.Errorf("tool pattern %q matches no tool; available tools: %s", pattern, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	read := &mcp.Tool{Name: "query_spans", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}}
+	write := &mcp.Tool{Name: "mute_monitor"}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		tool   *mcp.Tool
+		want   bool
+		reason string
+	}{
+		{"default", Policy{}, write, true, ""},
+		{"enabled", Policy{Enabled: []string{"query_*"}}, read, true, ""},
+		{"not enabled", Policy{Enabled: []string{"list_*"}}, read, false, "not in the enabled tools"},
+		{"disabled wins", Policy{Enabled: []string{"query_*"}, Disabled: []string{"query_spans"}}, read, false, `disabled by pattern "query_spans"`},
+		{"read-only", Policy{ReadOnly: true}, write, false, "read-only"},
+		{"read-only read", Policy{ReadOnly: true}, read, true, ""},
+	}
+	for _, tt := range tests {
+		got, reason := tt.policy.check(tt.tool)
+		if got != tt.want || !strings.Contains(reason, tt.reason) {
+			t.Errorf("%s: check(%s) = %v, %q; want %v, %q", tt.name, tt.tool.Name, got, reason, tt.want, tt.reason)
+		}
+	}
+}
+
+func TestCheckPatterns(t *testing.T) {
+	if err := CheckPatterns([]string{"query_*", "get_dashboard"}); err != nil {
+		t.Errorf("CheckPatterns: %v", err)
+	}
+	if err := CheckPatterns([]string{"query_metric"}); err == nil || !strings.Contains(err.Error(), "matches no tool") {
+		t.Errorf("CheckPatterns(query_metric) = %v, want a typo error", err)
+	}
+	if err := CheckPatterns([]string{"query_["}); err == nil || !strings.Contains(err.Error(), "invalid tool pattern") {
+		t.Errorf("CheckPatterns(query_[) = %v, want a malformed pattern error", err)
+	}
+}
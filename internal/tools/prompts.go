@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// playbook is a prompt expanding into instructions for an investigation
+// with the server's tools.
+type playbook struct {
+	prompt *mcp.Prompt
+	// tools lists the tools the instructions call. The prompt is only
+	// registered when the policy exposes all of them.
+	tools []string
+	// instructions returns the instructions for the given arguments, with
+	// relative times resolved against now.
+	instructions func(args map[string]string, now time.Time) (string, error)
+}
+
+// orgArgument is the argument of every prompt naming the org to investigate.
+var orgArgument = &mcp.PromptArgument{
+	Name:        "org",
+	Description: "Datadog org to investigate, as named in the server configuration. Defaults to the server's default org",
+}
+
+// playbooks lists the prompts of the server.
+var playbooks = []playbook{
+	{
+		prompt: &mcp.Prompt{
+			Name:        "triage_service",
+			Title:       "Triage a service",
+			Description: "Check a service's golden signals against the previous period, its errors and hot spots, and who owns it.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "service", Description: "The APM service to triage", Required: true},
+				{Name: "env", Description: "Environment, e.g. production. Defaults to all environments"},
+				{Name: "window", Description: "How far back to look, e.g. 15m, 1h or 1d. Defaults to 1h"},
+				orgArgument,
+			},
+		},
+		tools:        []string{"query_apm_stats", "query_spans", "query_metrics", "get_apm_services"},
+		instructions: triageService,
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "explain_dashboard",
+			Title:       "Explain a dashboard",
+			Description: "Explain what a dashboard monitors, what each widget shows and what its charts read now.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "dashboard_id", Description: "The dashboard ID, e.g. abc-123-def", Required: true},
+				orgArgument,
+			},
+		},
+		tools:        []string{"get_dashboard", "query_metrics"},
+		instructions: explainDashboard,
+	},
+	{
+		prompt: &mcp.Prompt{
+			Name:        "latency_regression",
+			Title:       "Investigate a latency regression",
+			Description: "Confirm a service's latency regression against the preceding period, and find when it started, where the time goes and why.",
+			Arguments: []*mcp.PromptArgument{
+				{Name: "service", Description: "The APM service whose latency regressed", Required: true},
+				{Name: "since", Description: "When the regression started, e.g. now-2h, yesterday or 2024-01-15 10:00 (UTC). Defaults to now-1h"},
+				{Name: "env", Description: "Environment, e.g. production. Defaults to all environments"},
+				orgArgument,
+			},
+		},
+		tools:        []string{"query_apm_stats", "query_metrics", "query_spans"},
+		instructions: latencyRegression,
+	},
+}
+
+// registerPrompts registers the prompts whose tools are all exposed.
+func registerPrompts(r *registry) {
+	if r.server == nil {
+		return
+	}
+
+	for _, pb := range playbooks {
+		if !slices.ContainsFunc(pb.tools, func(tool string) bool { return !r.exposed(tool) }) {
+			r.server.AddPrompt(pb.prompt, r.promptHandler(pb))
+		}
+	}
+}
+
+// promptHandler expands pb into a user message, after checking its
+// arguments.
+func (r *registry) promptHandler(pb playbook) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		args := req.Params.Arguments
+		for _, arg := range pb.prompt.Arguments {
+			if arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+				return nil, fmt.Errorf("missing required argument %q", arg.Name)
+			}
+		}
+		if _, err := r.orgs.Get(args["org"]); err != nil {
+			return nil, err
+		}
+
+		text, err := pb.instructions(args, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.GetPromptResult{
+			Description: pb.prompt.Description,
+			Messages: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: text}},
+			},
+		}, nil
+	}
+}
+
+// toolCall renders a call of tool with the given arguments, as alternating
+// names and values. Empty values are left out, and the org argument is added
+// when args names one.
+func toolCall(args map[string]string, tool string, kv ...string) string {
+	kv = append(kv, "org", args["org"])
+	var params []string
+	for i := 0; i+1 < len(kv); i += 2 {
+		if kv[i+1] != "" {
+			params = append(params, fmt.Sprintf("%s=%q", kv[i], kv[i+1]))
+		}
+	}
+	return fmt.Sprintf("%s(%s)", tool, strings.Join(params, ", "))
+}
+
+// scope returns the tag scope of a service's trace metrics.
+func scope(service, env string) string {
+	s := "service:" + service
+	if env != "" {
+		s += ",env:" + env
+	}
+	return s
+}
+
+// spanQuery returns the span search query selecting a service's spans.
+func spanQuery(service, env string, terms ...string) string {
+	q := "service:" + service
+	if env != "" {
+		q += " env:" + env
+	}
+	return strings.Join(append([]string{q}, terms...), " ")
+}
+
+func triageService(args map[string]string, now time.Time) (string, error) {
+	service, env := args["service"], args["env"]
+	window := args["window"]
+	if window == "" {
+		window = "1h"
+	}
+	start, err := parseTime("now-"+strings.TrimPrefix(window, "-"), now)
+	if err != nil || !start.Before(now) {
+		return "", fmt.Errorf("invalid window %q: use a duration such as 15m, 1h or 1d", window)
+	}
+	from, to := start.Format(time.RFC3339), now.Format(time.RFC3339)
+	baseFrom := start.Add(-now.Sub(start)).Format(time.RFC3339)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Triage the health of service %q", service)
+	if env != "" {
+		fmt.Fprintf(&b, " in env %q", env)
+	}
+	fmt.Fprintf(&b, " over the last %s (%s to %s), using the Datadog tools of this server. Keep tool output compact with detail=\"brief\" unless a step needs more.\n\n", window, from, to)
+
+	fmt.Fprintf(&b, "1. Golden signals: call %s for latency, error rate and throughput.\n",
+		toolCall(args, "query_apm_stats", "service", service, "env", env, "from", from, "to", to))
+	fmt.Fprintf(&b, "2. Baseline: call %s for the previous %s and compare each signal. Treat a change of more than 20%%, or an error rate above 1%%, as significant.\n",
+		toolCall(args, "query_apm_stats", "service", service, "env", env, "from", baseFrom, "to", from), window)
+	fmt.Fprintf(&b, "3. Errors: call %s and group the error spans by resource and error type. Follow a trace of the most frequent error with %s.\n",
+		toolCall(args, "query_spans", "query", spanQuery(service, env, "status:error"), "from", from, "to", to, "detail", "brief"),
+		toolCall(args, "query_spans", "query", "trace_id:<id>", "from", from, "to", to))
+	fmt.Fprintf(&b, "4. Hot spots: call %s to see which resources carry the traffic, and %s to find the slowest ones.\n",
+		toolCall(args, "query_metrics", "query", fmt.Sprintf("sum:trace.%s.hits{%s} by {resource_name}.as_count()", service, scope(service, env)), "from", from, "to", to, "detail", "brief"),
+		toolCall(args, "query_metrics", "query", fmt.Sprintf("p95:trace.%s.duration{%s} by {resource_name}", service, scope(service, env)), "from", from, "to", to, "detail", "brief"))
+	fmt.Fprintf(&b, "5. Ownership: call %s and find %q for its team, tier and contacts.\n\n",
+		toolCall(args, "get_apm_services", "detail", "brief"), service)
+
+	b.WriteString("Finish with a short report: a one-line verdict (healthy, degraded or down), the evidence for it with numbers from now and the baseline, the most likely cause, and who to contact. Say which steps returned no data rather than guessing.")
+	return b.String(), nil
+}
+
+func explainDashboard(args map[string]string, now time.Time) (string, error) {
+	id := args["dashboard_id"]
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Explain Datadog dashboard %q to an engineer who has never seen it, using the Datadog tools of this server.\n\n", id)
+
+	fmt.Fprintf(&b, "1. Call %s for its description, template variables and widgets.\n",
+		toolCall(args, "get_dashboard", "dashboard_id", id, "detail", "full"))
+	b.WriteString("2. Work out what the dashboard monitors: the services, hosts or business flows its widgets cover, grouped the way its layout groups them.\n")
+	fmt.Fprintf(&b, "3. For up to five of the most important timeseries widgets, run their metric queries with %s, substituting template variables with their defaults, to report what the charts read now.\n",
+		toolCall(args, "query_metrics", "query", "<widget query>", "from", "now-1h", "detail", "brief"))
+	b.WriteString("4. Note the template variables and what they switch between.\n\n")
+
+	b.WriteString("Finish with the dashboard's purpose in one or two sentences, then a section per widget group saying what each chart shows and what a bad reading looks like, the current readings from step 3, and anything that looks broken, such as widgets without data.")
+	return b.String(), nil
+}
+
+func latencyRegression(args map[string]string, now time.Time) (string, error) {
+	service, env := args["service"], args["env"]
+	since := args["since"]
+	if since == "" {
+		since = "now-1h"
+	}
+	start, err := parseTime(since, now)
+	if err != nil {
+		return "", fmt.Errorf("invalid 'since' time: %w", err)
+	}
+	if !start.Before(now) {
+		return "", fmt.Errorf("'since' time (%s) must be in the past", start.Format(time.RFC3339))
+	}
+	from, to := start.Format(time.RFC3339), now.Format(time.RFC3339)
+	baseFrom := start.Add(-now.Sub(start)).Format(time.RFC3339)
+	p95 := fmt.Sprintf("p95:trace.%s.duration{%s}", service, scope(service, env))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Investigate a latency regression of service %q", service)
+	if env != "" {
+		fmt.Fprintf(&b, " in env %q", env)
+	}
+	fmt.Fprintf(&b, " since %s, using the Datadog tools of this server. The regression window is %s to %s; the baseline is the preceding period of the same length, %s to %s.\n\n", since, from, to, baseFrom, from)
+
+	fmt.Fprintf(&b, "1. Confirm: call %s and %s, and compare avg and p95 latency. If latency did not rise, say so and stop.\n",
+		toolCall(args, "query_apm_stats", "service", service, "env", env, "from", from, "to", to),
+		toolCall(args, "query_apm_stats", "service", service, "env", env, "from", baseFrom, "to", from))
+	fmt.Fprintf(&b, "2. Localize: call %s for the regression window and the baseline, and find the resources whose p95 rose most. Check their throughput with the trace.%s.hits metric too: more load can explain slower requests.\n",
+		toolCall(args, "query_metrics", "query", p95+" by {resource_name}", "from", from, "to", to, "detail", "brief"), service)
+	fmt.Fprintf(&b, "3. Timing: call %s to find when latency stepped up, and whether it was sudden (a deploy or config change) or gradual (load or data growth).\n",
+		toolCall(args, "query_metrics", "query", p95, "from", baseFrom, "to", to, "detail", "full"))
+	fmt.Fprintf(&b, "4. Slow requests: call %s and compare the slowest spans with typical ones. Follow a slow trace with %s to see whether the time goes to a downstream service.\n",
+		toolCall(args, "query_spans", "query", spanQuery(service, env, "resource_name:<slowest resource>"), "from", from, "to", to, "detail", "full"),
+		toolCall(args, "query_spans", "query", "trace_id:<id>", "from", from, "to", to))
+	b.WriteString("5. Errors: check the error rate from step 1; timeouts and retries often show up as latency first.\n\n")
+
+	b.WriteString("Finish with the size of the regression (avg and p95, baseline against now), when it started, the resources and downstream dependencies involved, the most likely cause, and what to check next. Say which steps returned no data rather than guessing.")
+	return b.String(), nil
+}
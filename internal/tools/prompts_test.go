@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+// getPrompt expands the named prompt and returns its text.
+func getPrompt(t *testing.T, session *mcp.ClientSession, name string, args map[string]string) string {
+	t.Helper()
+	res, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if len(res.Messages) != 1 || res.Messages[0].Role != "user" {
+		t.Fatalf("%s: got %d messages, want one user message", name, len(res.Messages))
+	}
+	text, ok := res.Messages[0].Content.(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("%s: content is %T, want text", name, res.Messages[0].Content)
+	}
+	return text.Text
+}
+
+func TestPrompts(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text := getPrompt(t, session, "triage_service", map[string]string{"service": "checkout", "env": "prod", "window": "15m", "org": "default"})
+	assertContains(t, text,
+		`Triage the health of service "checkout" in env "prod" over the last 15m`,
+		`query_apm_stats(service="checkout", env="prod", from=`,
+		`query_spans(query="service:checkout env:prod status:error"`,
+		`sum:trace.checkout.hits{service:checkout,env:prod} by {resource_name}.as_count()`,
+		`get_apm_services(detail="brief", org="default")`,
+	)
+
+	text = getPrompt(t, session, "explain_dashboard", map[string]string{"dashboard_id": "abc-123-def"})
+	assertContains(t, text, `get_dashboard(dashboard_id="abc-123-def", detail="full")`, "query_metrics(")
+	if strings.Contains(text, "org=") {
+		t.Errorf("prompt names an org although none was given:\n%s", text)
+	}
+
+	text = getPrompt(t, session, "latency_regression", map[string]string{"service": "checkout", "since": "now-2h"})
+	assertContains(t, text, `Investigate a latency regression of service "checkout" since now-2h`, "p95:trace.checkout.duration{service:checkout}")
+}
+
+func TestPromptTimes(t *testing.T) {
+	now := time.Date(2025, 1, 15, 13, 0, 0, 0, time.UTC)
+
+	text, err := triageService(map[string]string{"service": "checkout"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, text,
+		"over the last 1h (2025-01-15T12:00:00Z to 2025-01-15T13:00:00Z)",
+		`from="2025-01-15T11:00:00Z", to="2025-01-15T12:00:00Z"`,
+	)
+
+	text, err = latencyRegression(map[string]string{"service": "checkout", "since": "2025-01-15 10:00"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, text, "The regression window is 2025-01-15T10:00:00Z to 2025-01-15T13:00:00Z; the baseline is the preceding period of the same length, 2025-01-15T07:00:00Z to 2025-01-15T10:00:00Z.")
+}
+
+func TestPromptErrors(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	tests := []struct {
+		name string
+		args map[string]string
+		want string
+	}{
+		{"triage_service", map[string]string{"service": " "}, `missing required argument "service"`},
+		{"explain_dashboard", nil, `missing required argument "dashboard_id"`},
+		{"triage_service", map[string]string{"service": "checkout", "window": "soon"}, `invalid window "soon"`},
+		{"triage_service", map[string]string{"service": "checkout", "window": "0m"}, `invalid window "0m"`},
+		{"latency_regression", map[string]string{"service": "checkout", "since": "last tuesday"}, "invalid 'since' time"},
+		{"latency_regression", map[string]string{"service": "checkout", "since": "now+1h"}, "must be in the past"},
+		{"latency_regression", map[string]string{"service": "checkout", "org": "eu1"}, `unknown org "eu1"`},
+	}
+	for _, tt := range tests {
+		_, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{Name: tt.name, Arguments: tt.args})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s(%v): err = %v, want %q", tt.name, tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestPromptsPolicy(t *testing.T) {
+	// Without query_spans, only the dashboard prompt can be followed.
+	session := newTestSession(t, fake.New(), Options{Policy: Policy{Disabled: []string{"query_spans"}}})
+
+	var names []string
+	for prompt, err := range session.Prompts(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, prompt.Name)
+	}
+	if !slices.Equal(names, []string{"explain_dashboard"}) {
+		t.Errorf("prompts = %v, want [explain_dashboard]", names)
+	}
+}
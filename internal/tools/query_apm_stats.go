@@ -3,11 +3,12 @@ package tools
 import (
 	"context"
 	"fmt"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
-	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // QueryAPMStatsInput defines the input for the query_apm_stats tool.
@@ -15,8 +16,11 @@ type QueryAPMStatsInput struct {
 	Service   string `json:"service" jsonschema:"The service name to query stats for"`
 	Operation string `json:"operation,omitempty" jsonschema:"Specific operation/resource name to filter by"`
 	Env       string `json:"env,omitempty" jsonschema:"Environment to filter by, e.g. production or staging"`
-	From      string `json:"from,omitempty" jsonschema:"Start time. Defaults to 1 hour ago"`
-	To        string `json:"to,omitempty" jsonschema:"End time. Defaults to now"`
+	From      string `json:"from,omitempty" jsonschema:"Start time: relative such as now-15m, now-1d or now-1w/w, today, yesterday, an epoch timestamp in seconds or milliseconds, RFC3339, or 2024-01-15 10:00 in the given timezone. Defaults to 1 hour before 'to' unless configured otherwise"`
+	To        string `json:"to,omitempty" jsonschema:"End time, in the same formats as 'from'. Defaults to now"`
+	Timezone  string `json:"timezone,omitempty" jsonschema:"IANA timezone, e.g. Europe/Paris, in which times without a UTC offset, today, yesterday and rounding such as now/d are read. Defaults to UTC"`
+	Org       string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
 // APMStatsResult contains APM statistics for a service.
@@ -30,12 +34,6 @@ type APMStatsResult struct {
 	Throughput *ThroughputStats `json:"throughput,omitempty"`
 }
 
-// TimeRange represents a time range.
-type TimeRange struct {
-	From time.Time `json:"from"`
-	To   time.Time `json:"to"`
-}
-
 // LatencyStats contains latency statistics.
 type LatencyStats struct {
 	Avg float64 `json:"avg_ms"`
@@ -57,31 +55,26 @@ type ThroughputStats struct {
 	TotalRequests     float64 `json:"total_requests"`
 }
 
-func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerQueryAPMStats(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "query_apm_stats",
 		Description: "Query APM statistics for a service including latency percentiles (p50, p95, p99), error rates, and throughput.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input QueryAPMStatsInput) (*mcp.CallToolResult, *APMStatsResult, error) {
-		var from, to time.Time
-		var err error
-
-		if input.From == "" {
-			from = time.Now().Add(-1 * time.Hour)
-		} else {
-			from, err = parseTime(input.From)
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid 'from' time: %w", err)
-			}
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if input.To == "" {
-			to = time.Now()
-		} else {
-			to, err = parseTime(input.To)
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid 'to' time: %w", err)
-			}
+		tr, err := r.timeRange("query_apm_stats", input.From, input.To, input.Timezone)
+		if err != nil {
+			return nil, nil, err
 		}
+		from, to := tr.From, tr.To
 
 		// Build tag filter
 		tags := fmt.Sprintf("service:%s", input.Service)
@@ -96,13 +89,16 @@ func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
 			Service:   input.Service,
 			Operation: input.Operation,
 			Env:       input.Env,
-			TimeRange: TimeRange{From: from, To: to},
+			TimeRange: tr,
 		}
 
 		// Query latency metrics (avg)
 		latencyQuery := fmt.Sprintf("avg:trace.%s.duration{%s}", input.Service, tags)
 		latencyResult, err := client.QueryMetrics(ctx, latencyQuery, from, to)
-		if err == nil && len(latencyResult.Series) > 0 {
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(latencyResult.Series) > 0 {
 			result.Latency = &LatencyStats{}
 			var sum float64
 			for _, dp := range latencyResult.Series[0].DataPoints {
@@ -116,7 +112,10 @@ func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
 		// Query p95 latency
 		p95Query := fmt.Sprintf("p95:trace.%s.duration{%s}", input.Service, tags)
 		p95Result, err := client.QueryMetrics(ctx, p95Query, from, to)
-		if err == nil && len(p95Result.Series) > 0 && result.Latency != nil {
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(p95Result.Series) > 0 && result.Latency != nil {
 			var sum float64
 			for _, dp := range p95Result.Series[0].DataPoints {
 				sum += dp.Value
@@ -129,7 +128,10 @@ func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
 		// Query error count
 		errorQuery := fmt.Sprintf("sum:trace.%s.errors{%s}.as_count()", input.Service, tags)
 		errorResult, err := client.QueryMetrics(ctx, errorQuery, from, to)
-		if err == nil && len(errorResult.Series) > 0 {
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(errorResult.Series) > 0 {
 			result.ErrorRate = &ErrorRateStats{}
 			for _, dp := range errorResult.Series[0].DataPoints {
 				result.ErrorRate.ErrorCount += dp.Value
@@ -139,7 +141,10 @@ func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
 		// Query hit count (total requests)
 		hitsQuery := fmt.Sprintf("sum:trace.%s.hits{%s}.as_count()", input.Service, tags)
 		hitsResult, err := client.QueryMetrics(ctx, hitsQuery, from, to)
-		if err == nil && len(hitsResult.Series) > 0 {
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(hitsResult.Series) > 0 {
 			if result.ErrorRate == nil {
 				result.ErrorRate = &ErrorRateStats{}
 			}
@@ -161,38 +166,81 @@ func registerQueryAPMStats(server *mcp.Server, client *datadog.Client) {
 		}
 
 		// Build summary text
-		summary := fmt.Sprintf("APM Stats for service: %s\n", input.Service)
+		text.Printf("APM Stats for service: %s\n", input.Service)
 		if input.Operation != "" {
-			summary += fmt.Sprintf("Operation: %s\n", input.Operation)
+			text.Printf("Operation: %s\n", input.Operation)
 		}
 		if input.Env != "" {
-			summary += fmt.Sprintf("Environment: %s\n", input.Env)
+			text.Printf("Environment: %s\n", input.Env)
 		}
-		summary += fmt.Sprintf("Time Range: %s to %s\n\n", from.Format(time.RFC3339), to.Format(time.RFC3339))
+		text.Printf("Time Range: %s\n\n", tr)
+
+		if result.Latency == nil && result.ErrorRate == nil {
+			text.Printf("No trace metrics found for this service in the time range; check the service, operation and env names with get_apm_services.\n")
+		} else if text.Format().Tabular() {
+			var stats [][]string
+			stat := func(name string, value float64, unit string) {
+				stats = append(stats, []string{name, strconv.FormatFloat(value, 'f', 2, 64), unit})
+			}
+			if result.Latency != nil {
+				stat("latency_avg", result.Latency.Avg, "ms")
+				stat("latency_p95", result.Latency.P95, "ms")
+			}
+			if result.ErrorRate != nil {
+				stat("errors", result.ErrorRate.ErrorCount, "requests")
+				stat("total", result.ErrorRate.TotalCount, "requests")
+				stat("error_rate", result.ErrorRate.ErrorPercent, "%")
+			}
+			if result.Throughput != nil {
+				stat("throughput", result.Throughput.RequestsPerSecond, "req/s")
+				stat("total_requests", result.Throughput.TotalRequests, "requests")
+			}
+			text.List(render.List{
+				Noun:    "statistics",
+				Count:   len(stats),
+				Item:    func(i int, detail render.Detail) string { return strings.Join(stats[i], " ") + "\n" },
+				Columns: []string{"statistic", "value", "unit"},
+				Rows: func(i int) [][]string {
+					return [][]string{stats[i]}
+				},
+			})
+		} else if text.Detail() == render.DetailBrief {
+			var parts []string
+			if result.Latency != nil {
+				parts = append(parts, fmt.Sprintf("avg %.2f ms, p95 %.2f ms", result.Latency.Avg, result.Latency.P95))
+			}
+			if result.ErrorRate != nil {
+				parts = append(parts, fmt.Sprintf("%.2f%% errors (%.0f/%.0f)", result.ErrorRate.ErrorPercent, result.ErrorRate.ErrorCount, result.ErrorRate.TotalCount))
+			}
+			if result.Throughput != nil {
+				parts = append(parts, fmt.Sprintf("%.2f req/s", result.Throughput.RequestsPerSecond))
+			}
+			text.Printf("%s\n", strings.Join(parts, "; "))
+		} else {
+			if result.Latency != nil {
+				text.Printf("Latency:\n")
+				text.Printf("  Avg: %.2f ms\n", result.Latency.Avg)
+				text.Printf("  P95: %.2f ms\n", result.Latency.P95)
+			}
 
-		if result.Latency != nil {
-			summary += "Latency:\n"
-			summary += fmt.Sprintf("  Avg: %.2f ms\n", result.Latency.Avg)
-			summary += fmt.Sprintf("  P95: %.2f ms\n", result.Latency.P95)
-		}
+			if result.ErrorRate != nil {
+				text.Printf("\nError Rate:\n")
+				text.Printf("  Errors: %.0f\n", result.ErrorRate.ErrorCount)
+				text.Printf("  Total: %.0f\n", result.ErrorRate.TotalCount)
+				text.Printf("  Rate: %.2f%%\n", result.ErrorRate.ErrorPercent)
+			}
 
-		if result.ErrorRate != nil {
-			summary += "\nError Rate:\n"
-			summary += fmt.Sprintf("  Errors: %.0f\n", result.ErrorRate.ErrorCount)
-			summary += fmt.Sprintf("  Total: %.0f\n", result.ErrorRate.TotalCount)
-			summary += fmt.Sprintf("  Rate: %.2f%%\n", result.ErrorRate.ErrorPercent)
+			if result.Throughput != nil {
+				text.Printf("\nThroughput:\n")
+				text.Printf("  Requests/sec: %.2f\n", result.Throughput.RequestsPerSecond)
+				text.Printf("  Total Requests: %.0f\n", result.Throughput.TotalRequests)
+			}
 		}
 
-		if result.Throughput != nil {
-			summary += "\nThroughput:\n"
-			summary += fmt.Sprintf("  Requests/sec: %.2f\n", result.Throughput.RequestsPerSecond)
-			summary += fmt.Sprintf("  Total Requests: %.0f\n", result.Throughput.TotalRequests)
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
 		}
-
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
-		}, result, nil
+		return res, result, nil
 	})
 }
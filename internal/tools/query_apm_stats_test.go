@@ -0,0 +1,68 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestQueryAPMStats(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[APMStatsResult](t, session, "query_apm_stats", withRange(map[string]any{"service": "checkout"}))
+	assertContains(t, text,
+		"APM Stats for service: checkout",
+		"Avg: 120.00 ms",
+		"P95: 310.00 ms",
+		"Errors: 5",
+		"Rate: 0.42%",
+		"Requests/sec: 0.33",
+	)
+	if out.Latency == nil || out.Latency.Avg != 120 {
+		t.Errorf("latency = %+v, want an average of 120ms", out.Latency)
+	}
+	if out.ErrorRate == nil || out.ErrorRate.ErrorCount != 5 || out.ErrorRate.TotalCount != 1200 {
+		t.Errorf("error rate = %+v, want 5 errors of 1200", out.ErrorRate)
+	}
+}
+
+// failingBackend fails every metric query with err.
+type failingBackend struct {
+	*fake.Backend
+	err error
+}
+
+func (b failingBackend) QueryMetrics(ctx context.Context, query string, from, to time.Time) (*datadog.QueryMetricsResult, error) {
+	return nil, b.err
+}
+
+func TestQueryAPMStatsErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   []string
+	}{
+		{http.StatusTooManyRequests, []string{"429 Too Many Requests", "Hint: Datadog's rate limit"}},
+		{http.StatusForbidden, []string{"403 Forbidden", "Hint: The application key of this org is not allowed"}},
+	}
+	for _, tt := range tests {
+		backend := failingBackend{Backend: fake.New(), err: datadog.NewAPIError("failed to query metrics", tt.status)}
+		session := newTestSession(t, backend, Options{})
+
+		text := callToolError(t, session, "query_apm_stats", withRange(map[string]any{"service": "checkout"}))
+		assertContains(t, text, tt.want...)
+	}
+}
+
+func TestQueryAPMStatsNoData(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[APMStatsResult](t, session, "query_apm_stats", withRange(map[string]any{"service": "nope"}))
+	assertContains(t, text, "No trace metrics found for this service")
+	if out.Latency != nil || out.ErrorRate != nil || out.Throughput != nil {
+		t.Errorf("result = %+v, want no statistics", out)
+	}
+}
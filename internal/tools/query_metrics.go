@@ -3,112 +3,232 @@ package tools
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // QueryMetricsInput defines the input for the query_metrics tool.
 type QueryMetricsInput struct {
 	Query         string `json:"query" jsonschema:"Datadog metric query string, e.g. avg:system.cpu.user{*} by {host}"`
-	From          string `json:"from,omitempty" jsonschema:"Start time in RFC3339 format or relative, e.g. now-1h. Defaults to 1 hour ago"`
-	To            string `json:"to,omitempty" jsonschema:"End time in RFC3339 format or relative, e.g. now. Defaults to now"`
-	MaxDataPoints int    `json:"max_data_points,omitempty" jsonschema:"Maximum number of data points to return per series. Defaults to 300. Use 0 for unlimited."`
-	MaxSeries     int    `json:"max_series,omitempty" jsonschema:"Maximum number of series to return. Defaults to 100. Use 0 for unlimited."`
+	From          string `json:"from,omitempty" jsonschema:"Start time: relative such as now-15m, now-1d or now-1w/w, today, yesterday, an epoch timestamp in seconds or milliseconds, RFC3339, or 2024-01-15 10:00 in the given timezone. Defaults to 1 hour before 'to' unless configured otherwise"`
+	To            string `json:"to,omitempty" jsonschema:"End time, in the same formats as 'from'. Defaults to now"`
+	Timezone      string `json:"timezone,omitempty" jsonschema:"IANA timezone, e.g. Europe/Paris, in which times without a UTC offset, today, yesterday and rounding such as now/d are read. Defaults to UTC"`
+	MaxDataPoints int    `json:"max_data_points,omitempty" jsonschema:"Maximum number of data points to return per series. Defaults to 300 unless configured otherwise."`
+	Downsample    string `json:"downsample,omitempty" jsonschema:"How series with more than max_data_points points are reduced: lttb (default) keeps their shape and spikes over the whole range, avg averages buckets of points, max keeps the highest point of each bucket, and tail keeps only the most recent points"`
+	MaxSeries     int    `json:"max_series,omitempty" jsonschema:"Maximum number of series to return. Defaults to 100 unless configured otherwise."`
+	SortBy        string `json:"sort_by,omitempty" jsonschema:"Sort series by a statistic: min, max, mean, last, p50, p95, slope or peak_at. Series are sorted before max_series applies, so this picks the top series. Defaults to Datadog's order"`
+	SortOrder     string `json:"sort_order,omitempty" jsonschema:"Order of sort_by: desc (default) or asc"`
+	Org           string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerQueryMetrics(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerQueryMetrics(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "query_metrics",
 		Description: "Query timeseries metrics data from Datadog. Returns metric values over a time range with support for aggregations and grouping.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input QueryMetricsInput) (*mcp.CallToolResult, *datadog.QueryMetricsResult, error) {
-		var from, to time.Time
-		var err error
-
-		if input.From == "" {
-			from = time.Now().Add(-1 * time.Hour)
-		} else {
-			from, err = parseTime(input.From)
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid 'from' time: %w", err)
-			}
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if input.To == "" {
-			to = time.Now()
-		} else {
-			to, err = parseTime(input.To)
-			if err != nil {
-				return nil, nil, fmt.Errorf("invalid 'to' time: %w", err)
-			}
+		strategy, err := parseDownsample(input.Downsample)
+		if err != nil {
+			return nil, nil, err
+		}
+		sortBy, ascending, err := parseSort(input.SortBy, input.SortOrder)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		if from.After(to) {
-			return nil, nil, fmt.Errorf("'from' time must be before 'to' time")
+		tr, err := r.timeRange("query_metrics", input.From, input.To, input.Timezone)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		result, err := client.QueryMetrics(ctx, input.Query, from, to)
+		result, err := client.QueryMetrics(ctx, input.Query, tr.From, tr.To)
 		if err != nil {
 			return nil, nil, err
 		}
+		result.Warnings = enrichSeries(ctx, client, result.Series)
+
+		summarizeSeries(result.Series, sortBy, ascending)
 
 		// Apply pagination limits
 		maxSeries := input.MaxSeries
 		if maxSeries <= 0 {
-			maxSeries = 100
+			maxSeries = r.limits.MaxSeries
 		}
 		maxDataPoints := input.MaxDataPoints
 		if maxDataPoints <= 0 {
-			maxDataPoints = 300
+			maxDataPoints = r.limits.MaxDataPoints
 		}
-
 		totalSeries := len(result.Series)
-		truncatedSeries := false
-		truncatedDataPoints := false
-
-		// Limit number of series
-		if maxSeries > 0 && len(result.Series) > maxSeries {
-			result.Series = result.Series[:maxSeries]
-			truncatedSeries = true
+		var truncatedSeries, downsampled bool
+		result.Series, truncatedSeries, downsampled = limitSeries(result.Series, maxSeries, maxDataPoints, strategy)
+		if downsampled {
+			result.Downsample = strategy
 		}
 
-		// Limit data points per series
-		for i := range result.Series {
-			if maxDataPoints > 0 && len(result.Series[i].DataPoints) > maxDataPoints {
-				result.Series[i].DataPoints = result.Series[i].DataPoints[:maxDataPoints]
-				truncatedDataPoints = true
-			}
+		text.Printf("Query: %s\nTime Range: %s\nSeries Count: %d", input.Query, tr, len(result.Series))
+		if truncatedSeries {
+			text.Printf(" (truncated from %d, use max_series to see more)", totalSeries)
+		}
+		text.Printf("\n")
+		for _, w := range result.Warnings {
+			text.Printf("Warning: %s\n", w)
 		}
+		text.Printf("\n")
 
-		summary := fmt.Sprintf("Query: %s\nTime Range: %s to %s\nSeries Count: %d",
-			input.Query, from.Format(time.RFC3339), to.Format(time.RFC3339), len(result.Series))
+		text.List(seriesList(result.Series, text, strategy))
 
-		if truncatedSeries {
-			summary += fmt.Sprintf(" (truncated from %d, use max_series to see more)", totalSeries)
+		// Add the resolved range and pagination info to result
+		result.From, result.To = tr.From, tr.To
+		result.TotalSeries = totalSeries
+		result.Truncated = truncatedSeries || downsampled && strategy == DownsampleTail
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
 		}
-		summary += "\n"
+		return res, result, nil
+	})
+}
 
-		for i, series := range result.Series {
-			dataPointInfo := fmt.Sprintf("%d data points", len(series.DataPoints))
-			if truncatedDataPoints {
-				dataPointInfo += " (truncated, use max_data_points to see more)"
+// limitSeries keeps the first maxSeries series, and downsamples those with
+// more than maxDataPoints points with strategy. It labels each series with
+// its effective resolution, and reports whether series were cut and whether
+// any was downsampled.
+func limitSeries(series []datadog.MetricSeries, maxSeries, maxDataPoints int, strategy string) ([]datadog.MetricSeries, bool, bool) {
+	truncatedSeries, downsampled := false, false
+	if maxSeries > 0 && len(series) > maxSeries {
+		series = series[:maxSeries]
+		truncatedSeries = true
+	}
+	for i := range series {
+		s := &series[i]
+		res := resolution(s.DataPoints)
+		if maxDataPoints > 0 && len(s.DataPoints) > maxDataPoints {
+			// Each point left stands for several, except with tail.
+			if strategy != DownsampleTail {
+				res = res * time.Duration(len(s.DataPoints)) / time.Duration(maxDataPoints)
+			}
+			s.OriginalPoints = len(s.DataPoints)
+			s.DataPoints = downsample(s.DataPoints, maxDataPoints, strategy)
+			downsampled = true
+		}
+		s.Resolution = res.Seconds()
+	}
+	return series, truncatedSeries, downsampled
+}
+
+// seriesList lists series in text: one line per series with its stats, and
+// its values at full detail, rendered in a readable unit. strategy names how
+// the series with OriginalPoints were downsampled.
+func seriesList(series []datadog.MetricSeries, text *render.Text, strategy string) render.List {
+	// Tables list one row per series, or one per data point at full
+	// detail and in CSV, ready to chart in a spreadsheet.
+	// Table values stay in the series' own unit, named in a column.
+	columns := []string{"#", "metric", "tags", "points", "unit", "min", "max", "mean", "last", "p95", "trend"}
+	rows := func(i int) [][]string {
+		s := series[i]
+		row := []string{strconv.Itoa(i + 1), s.Metric, strings.Join(s.Tags, ","), strconv.Itoa(len(s.DataPoints)), unitName(s), "", "", "", "", "", ""}
+		if st := s.Stats; st != nil {
+			for j, v := range []float64{st.Min, st.Max, st.Mean, st.Last, st.P95} {
+				row[5+j] = strconv.FormatFloat(v, 'g', 6, 64)
+			}
+			row[10] = st.Trend
+		}
+		return [][]string{row}
+	}
+	if text.Detail() == render.DetailFull || text.Format() == render.FormatCSV {
+		columns = []string{"metric", "tags", "timestamp", "value", "unit"}
+		rows = func(i int) [][]string {
+			s := series[i]
+			tags := strings.Join(s.Tags, ",")
+			if len(s.DataPoints) == 0 {
+				return [][]string{{s.Metric, tags, "", "", unitName(s)}}
 			}
-			summary += fmt.Sprintf("\n[%d] %s (%s)", i+1, series.Metric, dataPointInfo)
-			if len(series.Tags) > 0 {
-				summary += fmt.Sprintf(" - Tags: %v", series.Tags)
+			rows := make([][]string, len(s.DataPoints))
+			for j, dp := range s.DataPoints {
+				rows[j] = []string{s.Metric, tags, dp.Timestamp.UTC().Format(time.RFC3339), strconv.FormatFloat(dp.Value, 'g', -1, 64), unitName(s)}
 			}
+			return rows
 		}
+	}
+
+	return render.List{
+		Noun:  "series",
+		Count: len(series),
+		Item: func(i int, detail render.Detail) string {
+			s := series[i]
+			vf := seriesFormat(s.Unit, s.PerUnit, 0)
+			if s.Stats != nil {
+				vf = seriesFormat(s.Unit, s.PerUnit, max(math.Abs(s.Stats.Min), math.Abs(s.Stats.Max)))
+			}
+			if detail == render.DetailBrief {
+				points := fmt.Sprintf("%d points", len(s.DataPoints))
+				if s.OriginalPoints > 0 {
+					points = fmt.Sprintf("%d of %d points, %s", len(s.DataPoints), s.OriginalPoints, strategy)
+				}
+				line := fmt.Sprintf("[%d] %s {%s} (%s)", i+1, s.Metric, strings.Join(s.Tags, ","), points)
+				if s.Stats != nil {
+					line += " " + formatStats(s.Stats, detail, vf)
+				}
+				return line + "\n"
+			}
 
-		// Add pagination info to result
-		result.TotalSeries = totalSeries
-		result.Truncated = truncatedSeries || truncatedDataPoints
+			dataPointInfo := fmt.Sprintf("%d data points", len(s.DataPoints))
+			if s.Type != "" {
+				dataPointInfo = s.Type + ", " + dataPointInfo
+			}
+			switch {
+			case s.OriginalPoints > 0 && strategy == DownsampleTail:
+				dataPointInfo += fmt.Sprintf(", the most recent of %d; use max_data_points to see more", s.OriginalPoints)
+			case s.OriginalPoints > 0:
+				dataPointInfo += fmt.Sprintf(", downsampled from %d with %s", s.OriginalPoints, strategy)
+			}
+			if s.Resolution > 0 {
+				dataPointInfo += ", every ~" + formatDuration(time.Duration(s.Resolution*float64(time.Second)))
+			}
+			line := fmt.Sprintf("[%d] %s (%s)", i+1, s.Metric, dataPointInfo)
+			if len(s.Tags) > 0 {
+				line += fmt.Sprintf(" - Tags: %v", s.Tags)
+			}
+			line += "\n"
+			if s.Stats != nil {
+				line += "    " + formatStats(s.Stats, detail, vf) + "\n"
+			}
+			if detail == render.DetailFull && len(s.DataPoints) > 0 {
+				values := make([]string, len(s.DataPoints))
+				for j, dp := range s.DataPoints {
+					values[j] = dp.Timestamp.UTC().Format("15:04:05") + "=" + vf.format(dp.Value)
+				}
+				line += "    " + strings.Join(values, " ") + "\n"
+			}
+			return line
+		},
+		Columns: columns,
+		Rows:    rows,
+	}
+}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
-		}, result, nil
-	})
+// unitName names the unit of a series, e.g. byte/second.
+func unitName(s datadog.MetricSeries) string {
+	if s.PerUnit != "" {
+		return s.Unit + "/" + s.PerUnit
+	}
+	return s.Unit
 }
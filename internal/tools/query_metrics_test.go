@@ -0,0 +1,207 @@
+package tools
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestQueryMetrics(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:system.cpu.user{*} by {host}",
+	}))
+	assertContains(t, text,
+		"Query: avg:system.cpu.user{*} by {host}",
+		"Time Range: 2025-01-15T12:00:00Z to 2025-01-15T13:00:00Z",
+		"Series Count: 2",
+		"[1] system.cpu.user (gauge, 4 data points, every ~20m) - Tags: [host:web-1]",
+		"min=20% max=60% mean=30.75% last=60%",
+	)
+
+	if len(out.Series) != 2 {
+		t.Fatalf("got %d series, want 2", len(out.Series))
+	}
+	s := out.Series[1]
+	if s.Unit != "percent" || s.Type != "gauge" {
+		t.Errorf("unit, type = %q, %q, want percent, gauge", s.Unit, s.Type)
+	}
+	if len(s.DataPoints) != 4 || s.DataPoints[3].Value != 60 {
+		t.Errorf("data points = %v, want 4 ending with 60", s.DataPoints)
+	}
+	if s.Stats == nil || s.Stats.Max != 60 || s.Stats.Trend != "rising" {
+		t.Errorf("stats = %+v, want max 60, rising", s.Stats)
+	}
+}
+
+func TestQueryMetricsLimits(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query":           "avg:system.cpu.user{*} by {host}",
+		"max_series":      1,
+		"max_data_points": 3,
+	}))
+	assertContains(t, text, "Series Count: 1 (truncated from 2", "3 data points, downsampled from 4 with lttb")
+	if !out.Truncated || out.TotalSeries != 2 || len(out.Series) != 1 || len(out.Series[0].DataPoints) != 3 {
+		t.Errorf("got %d of %d series (truncated %v); want 1 of 2 with 3 points, truncated", len(out.Series), out.TotalSeries, out.Truncated)
+	}
+}
+
+func TestQueryMetricsUnits(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, _ := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:trace.checkout.duration{service:checkout}",
+	}))
+	assertContains(t, text, "max=130ms")
+
+	text, _ = callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:system.mem.used{*}",
+	}))
+	assertContains(t, text, "GiB")
+}
+
+func TestQueryMetricsMetadata(t *testing.T) {
+	backend := fake.New()
+	backend.Series = append(backend.Series, fake.Series{Query: "avg:custom.queue.depth{*}", Metric: "custom.queue.depth", Values: []float64{3, 4}})
+	session := newTestSession(t, backend, Options{})
+
+	// Both series share a metric, looked up once.
+	text, _ := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:system.cpu.user{*} by {host}",
+	}))
+	if n := countCalls(backend, "GetMetricMetadata"); n != 1 {
+		t.Errorf("GetMetricMetadata called %d times, want 1", n)
+	}
+	if strings.Contains(text, "Warning") {
+		t.Errorf("unexpected warning in:\n%s", text)
+	}
+
+	// Metrics without metadata are no cause for a warning.
+	text, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:custom.queue.depth{*}",
+	}))
+	if len(out.Warnings) > 0 || strings.Contains(text, "Warning") {
+		t.Errorf("warnings = %v, want none for a metric without metadata", out.Warnings)
+	}
+
+	backend.Errors = map[string]error{"GetMetricMetadata": datadog.NewAPIError("failed to get metric metadata", http.StatusForbidden)}
+	text, out = callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:system.cpu.user{*}",
+	}))
+	assertContains(t, text,
+		"Warning: types and units left out of system.cpu.user: ",
+		"Datadog returned 403 Forbidden",
+		"[1] system.cpu.user (5 data points",
+	)
+	if len(out.Series) != 1 || out.Series[0].Type != "" || len(out.Warnings) != 1 {
+		t.Errorf("series = %+v, warnings = %v; want the series without a type and one warning", out.Series, out.Warnings)
+	}
+}
+
+func TestQueryMetricsMetadataNearDeadline(t *testing.T) {
+	backend := fake.New()
+	session := newTestSession(t, backend, Options{Timeouts: map[string]time.Duration{"query_metrics": time.Second}})
+
+	text, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query": "avg:system.cpu.user{*}",
+	}))
+	assertContains(t, text, "Warning: types and units left out: the tool timeout is too close")
+	if len(out.Series) != 1 || len(out.Series[0].DataPoints) != 5 {
+		t.Errorf("series = %+v, want the 5 points of cpu", out.Series)
+	}
+	if slices.Contains(backend.Calls(), "GetMetricMetadata") {
+		t.Errorf("calls = %v, want no metadata lookup", backend.Calls())
+	}
+}
+
+// countCalls counts the calls backend got to method.
+func countCalls(backend *fake.Backend, method string) int {
+	n := 0
+	for _, c := range backend.Calls() {
+		if c == method {
+			n++
+		}
+	}
+	return n
+}
+
+func TestQueryMetricsFormats(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, _ := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query":         "avg:system.cpu.user{*} by {host}",
+		"output_format": "markdown",
+	}))
+	assertContains(t, text, "| # | metric | tags |", "| host:web-2 |")
+
+	text, _ = callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query":         "avg:system.cpu.user{*} by {host}",
+		"output_format": "csv",
+	}))
+	assertContains(t, text, "metric,tags,timestamp,value,unit", "system.cpu.user,host:web-2,2025-01-15T13:00:00Z,60,percent")
+}
+func TestQueryMetricsSortAndLimit(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+		"query":      "avg:system.cpu.user{*} by {host}",
+		"sort_by":    "max",
+		"max_series": 1,
+	}))
+	assertContains(t, text, "Series Count: 1 (truncated from 2", "Tags: [host:web-2]")
+	if !out.Truncated || out.TotalSeries != 2 || len(out.Series) != 1 || out.Series[0].Tags[0] != "host:web-2" {
+		t.Errorf("got %d of %d series (truncated %v), first %v; want host:web-2, 1 of 2, truncated", len(out.Series), out.TotalSeries, out.Truncated, out.Series[0].Tags)
+	}
+
+	callToolError(t, session, "query_metrics", withRange(map[string]any{
+		"query":   "avg:system.cpu.user{*}",
+		"sort_by": "median",
+	}))
+}
+
+func TestQueryMetricsDownsample(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	// Every strategy keeps the spike of 40.1; tail drops the oldest points.
+	tests := []struct {
+		strategy  string
+		truncated bool
+		first     float64
+	}{
+		{"lttb", false, 12.5},
+		{"max", false, 12.5},
+		{"tail", true, 13.2},
+	}
+	for _, tt := range tests {
+		_, out := callTool[datadog.QueryMetricsResult](t, session, "query_metrics", withRange(map[string]any{
+			"query":           "avg:system.cpu.user{*}",
+			"max_data_points": 3,
+			"downsample":      tt.strategy,
+		}))
+		s := out.Series[0]
+		if len(s.DataPoints) != 3 || s.OriginalPoints != 5 {
+			t.Errorf("%s: got %d of %d points, want 3 of 5", tt.strategy, len(s.DataPoints), s.OriginalPoints)
+		}
+		if out.Truncated != tt.truncated {
+			t.Errorf("%s: truncated = %v, want %v", tt.strategy, out.Truncated, tt.truncated)
+		}
+		var peak float64
+		for _, p := range s.DataPoints {
+			peak = max(peak, p.Value)
+		}
+		if peak != 40.1 {
+			t.Errorf("%s: highest point = %v, want 40.1", tt.strategy, peak)
+		}
+		if s.DataPoints[0].Value != tt.first {
+			t.Errorf("%s: first point = %v, want %v", tt.strategy, s.DataPoints[0].Value, tt.first)
+		}
+	}
+}
@@ -3,58 +3,133 @@ package tools
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
 )
 
 // QuerySpansInput defines the input for the query_spans tool.
 type QuerySpansInput struct {
-	Query  string `json:"query,omitempty" jsonschema:"Span search query, e.g. service:my-service or @http.status_code:500. Defaults to * (all spans)"`
-	From   string `json:"from,omitempty" jsonschema:"Start time, e.g. now-15m or now-1h. Defaults to now-15m"`
-	To     string `json:"to,omitempty" jsonschema:"End time, e.g. now. Defaults to now"`
-	Limit  int32  `json:"limit,omitempty" jsonschema:"Maximum number of spans to return (1-1000). Defaults to 50"`
-	Cursor string `json:"cursor,omitempty" jsonschema:"Pagination cursor from previous response to get next page of results"`
+	Query    string `json:"query,omitempty" jsonschema:"Span search query, e.g. service:my-service or @http.status_code:500. Defaults to * (all spans)"`
+	From     string `json:"from,omitempty" jsonschema:"Start time: relative such as now-15m, now-1d or now-1w/w, today, yesterday, an epoch timestamp in seconds or milliseconds, RFC3339, or 2024-01-15 10:00 in the given timezone. Defaults to 15 minutes before 'to' unless configured otherwise"`
+	To       string `json:"to,omitempty" jsonschema:"End time, in the same formats as 'from'. Defaults to now"`
+	Timezone string `json:"timezone,omitempty" jsonschema:"IANA timezone, e.g. Europe/Paris, in which times without a UTC offset, today, yesterday and rounding such as now/d are read. Defaults to UTC"`
+	Limit    int32  `json:"limit,omitempty" jsonschema:"Maximum number of spans to return (1-1000). Defaults to 50"`
+	Cursor   string `json:"cursor,omitempty" jsonschema:"Pagination cursor from previous response to get next page of results"`
+	Org      string `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
 }
 
-func registerQuerySpans(server *mcp.Server, client *datadog.Client) {
-	mcp.AddTool(server, &mcp.Tool{
+func registerQuerySpans(r *registry) {
+	addTool(r, &mcp.Tool{
 		Name:        "query_spans",
 		Description: "Query APM spans/traces from Datadog. Search for specific spans by service, operation, status code, or custom tags.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
 	}, func(ctx context.Context, req *mcp.CallToolRequest, input QuerySpansInput) (*mcp.CallToolResult, *datadog.QuerySpansResult, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
 		query := input.Query
 		if query == "" {
 			query = "*"
 		}
 
-		result, err := client.QuerySpans(ctx, query, input.From, input.To, input.Limit, input.Cursor)
+		tr, err := r.timeRange("query_spans", input.From, input.To, input.Timezone)
 		if err != nil {
 			return nil, nil, err
 		}
 
-		summary := fmt.Sprintf("Found %d spans matching query: %s\n\n", result.TotalCount, query)
+		result, err := client.QuerySpans(ctx, query, tr.From, tr.To, input.Limit, input.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
 
-		for i, span := range result.Spans {
-			if i >= 20 {
-				summary += fmt.Sprintf("\n... and %d more spans (see structured output for full results)", result.TotalCount-20)
-				break
-			}
-			summary += fmt.Sprintf("[%d] %s / %s\n", i+1, span.Service, span.Name)
-			summary += fmt.Sprintf("    Resource: %s\n", span.Resource)
-			summary += fmt.Sprintf("    Status: %s, Duration: %.2fms\n", span.Status, float64(span.Duration)/1e6)
-			summary += fmt.Sprintf("    TraceID: %s, SpanID: %s\n", span.TraceID, span.SpanID)
-			summary += "\n"
+		text.Printf("Found %d spans matching query: %s\nTime Range: %s\n\n", result.TotalCount, query, tr)
+
+		columns := []string{"service", "name", "resource", "status", "duration_ms", "trace_id", "span_id"}
+		switch text.Detail() {
+		case render.DetailBrief:
+			columns = []string{"service", "name", "status", "duration_ms", "trace_id"}
+		case render.DetailFull:
+			columns = append(columns, "parent_id", "type", "start", "tags")
 		}
 
+		text.List(render.List{
+			Noun:  "spans",
+			Count: len(result.Spans),
+			Max:   r.limits.MaxSpans,
+			Item: func(i int, detail render.Detail) string {
+				span := result.Spans[i]
+				duration := float64(span.Duration) / 1e6
+				if detail == render.DetailBrief {
+					return fmt.Sprintf("[%d] %s / %s  %s  %.2fms  trace %s\n", i+1, span.Service, span.Name, span.Status, duration, span.TraceID)
+				}
+
+				s := fmt.Sprintf("[%d] %s / %s\n", i+1, span.Service, span.Name)
+				s += fmt.Sprintf("    Resource: %s\n", span.Resource)
+				s += fmt.Sprintf("    Status: %s, Duration: %.2fms\n", span.Status, duration)
+				s += fmt.Sprintf("    TraceID: %s, SpanID: %s\n", span.TraceID, span.SpanID)
+				if detail == render.DetailFull {
+					if span.ParentID != "" {
+						s += fmt.Sprintf("    ParentID: %s\n", span.ParentID)
+					}
+					if span.Type != "" {
+						s += fmt.Sprintf("    Type: %s\n", span.Type)
+					}
+					if !span.Start.IsZero() {
+						s += fmt.Sprintf("    Start: %s\n", span.Start.Format(time.RFC3339Nano))
+					}
+					for _, key := range slices.Sorted(maps.Keys(span.Tags)) {
+						s += fmt.Sprintf("    %s: %s\n", key, span.Tags[key])
+					}
+				}
+				return s + "\n"
+			},
+			Columns: columns,
+			Rows: func(i int) [][]string {
+				span := result.Spans[i]
+				duration := strconv.FormatFloat(float64(span.Duration)/1e6, 'f', 2, 64)
+				switch text.Detail() {
+				case render.DetailBrief:
+					return [][]string{{span.Service, span.Name, span.Status, duration, span.TraceID}}
+				case render.DetailFull:
+					var start string
+					if !span.Start.IsZero() {
+						start = span.Start.Format(time.RFC3339Nano)
+					}
+					tags := make([]string, 0, len(span.Tags))
+					for _, key := range slices.Sorted(maps.Keys(span.Tags)) {
+						tags = append(tags, key+":"+span.Tags[key])
+					}
+					return [][]string{{span.Service, span.Name, span.Resource, span.Status, duration, span.TraceID, span.SpanID,
+						span.ParentID, span.Type, start, strings.Join(tags, ",")}}
+				default:
+					return [][]string{{span.Service, span.Name, span.Resource, span.Status, duration, span.TraceID, span.SpanID}}
+				}
+			},
+		})
+
 		if result.NextCursor != "" {
-			summary += fmt.Sprintf("\nNext page cursor: %s", result.NextCursor)
+			text.Printf("\nNext page cursor: %s", result.NextCursor)
 		}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: summary},
-			},
-		}, result, nil
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
 	})
 }
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestQuerySpans(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.QuerySpansResult](t, session, "query_spans", map[string]any{"query": "service:checkout status:error"})
+	assertContains(t, text,
+		"Found 1 spans matching query: service:checkout status:error",
+		"[1] checkout / POST /cart/checkout",
+		"Status: error, Duration: 950.00ms",
+		"TraceID: 1002, SpanID: 2002",
+	)
+	if len(out.Spans) != 1 || out.Spans[0].SpanID != "2002" {
+		t.Errorf("spans = %+v, want span 2002", out.Spans)
+	}
+}
+
+func TestQuerySpansPagination(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	_, out := callTool[datadog.QuerySpansResult](t, session, "query_spans", map[string]any{"query": "*", "limit": 2})
+	if len(out.Spans) != 2 || out.NextCursor == "" {
+		t.Fatalf("first page: %d spans, cursor %q; want 2 and a cursor", len(out.Spans), out.NextCursor)
+	}
+
+	_, out = callTool[datadog.QuerySpansResult](t, session, "query_spans", map[string]any{"query": "*", "limit": 2, "cursor": out.NextCursor})
+	if len(out.Spans) != 1 || out.Spans[0].SpanID != "2003" || out.NextCursor != "" {
+		t.Errorf("second page: %+v, cursor %q; want span 2003 and no cursor", out.Spans, out.NextCursor)
+	}
+}
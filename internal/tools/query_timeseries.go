@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// TimeseriesQueryInput defines a named query of the query_timeseries tool.
+type TimeseriesQueryInput struct {
+	Name       string   `json:"name" jsonschema:"Name formulas refer to the query by, e.g. a"`
+	DataSource string   `json:"data_source,omitempty" jsonschema:"metrics (default), cloud_cost, logs, rum or dora"`
+	Query      string   `json:"query" jsonschema:"For metrics and cloud_cost, a metric query such as sum:trace.web.request.errors{env:prod} by {service}; for logs, rum and dora, a search query such as service:web status:error"`
+	Aggregator string   `json:"aggregator,omitempty" jsonschema:"For metrics, the space aggregator avg, sum, min or max, replacing the query's own. For logs, rum and dora, the computation: count (default), cardinality, sum, min, max, avg, pc75, pc90, pc95, pc98 or pc99"`
+	Metric     string   `json:"metric,omitempty" jsonschema:"For logs, rum and dora, the measure to compute, e.g. @duration. Not needed to count"`
+	GroupBy    []string `json:"group_by,omitempty" jsonschema:"For logs, rum and dora, facets to group by, e.g. service or @http.status_code. Group metric queries with by {tag} in the query"`
+}
+
+// QueryTimeseriesInput defines the input for the query_timeseries tool.
+type QueryTimeseriesInput struct {
+	Queries       []TimeseriesQueryInput `json:"queries" jsonschema:"Named metric and event queries"`
+	Formulas      []string               `json:"formulas,omitempty" jsonschema:"Formulas over the query names, e.g. a / b * 100 or a - b. Each formula returns its own series. Without formulas, each query's series are returned"`
+	From          string                 `json:"from,omitempty" jsonschema:"Start time: relative such as now-15m, now-1d or now-1w/w, today, yesterday, an epoch timestamp in seconds or milliseconds, RFC3339, or 2024-01-15 10:00 in the given timezone. Defaults to 1 hour before 'to' unless configured otherwise"`
+	To            string                 `json:"to,omitempty" jsonschema:"End time, in the same formats as 'from'. Defaults to now"`
+	Timezone      string                 `json:"timezone,omitempty" jsonschema:"IANA timezone, e.g. Europe/Paris, in which times without a UTC offset, today, yesterday and rounding such as now/d are read. Defaults to UTC"`
+	Interval      string                 `json:"interval,omitempty" jsonschema:"Time between points, e.g. 30s, 5m, 1h or 1d. Defaults to an interval Datadog picks for the time range"`
+	MaxDataPoints int                    `json:"max_data_points,omitempty" jsonschema:"Maximum number of data points to return per series. Defaults to 300 unless configured otherwise."`
+	Downsample    string                 `json:"downsample,omitempty" jsonschema:"How series with more than max_data_points points are reduced: lttb (default) keeps their shape and spikes over the whole range, avg averages buckets of points, max keeps the highest point of each bucket, and tail keeps only the most recent points"`
+	MaxSeries     int                    `json:"max_series,omitempty" jsonschema:"Maximum number of series to return. Defaults to 100 unless configured otherwise."`
+	SortBy        string                 `json:"sort_by,omitempty" jsonschema:"Sort series by a statistic: min, max, mean, last, p50, p95, slope or peak_at. Series are sorted before max_series applies, so this picks the top series. Defaults to Datadog's order"`
+	SortOrder     string                 `json:"sort_order,omitempty" jsonschema:"Order of sort_by: desc (default) or asc"`
+	Org           string                 `json:"org,omitempty" jsonschema:"Datadog org to query, as named in the server configuration. Defaults to the server's default org"`
+	OutputOptions
+}
+
+func registerQueryTimeseries(r *registry) {
+	addTool(r, &mcp.Tool{
+		Name:        "query_timeseries",
+		Description: "Query timeseries from Datadog with several named metric, log, RUM or DORA queries combined by formulas, such as error ratios (a / b * 100) or differences between metrics. Supports per-query aggregators and the interval between points.",
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}, func(ctx context.Context, req *mcp.CallToolRequest, input QueryTimeseriesInput) (*mcp.CallToolResult, *datadog.QueryTimeseriesResult, error) {
+		client, err := r.orgs.Get(input.Org)
+		if err != nil {
+			return nil, nil, err
+		}
+		text, err := r.text(input.OutputOptions)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(input.Queries) == 0 {
+			return nil, nil, fmt.Errorf("at least one query is required")
+		}
+		queries := make([]datadog.TimeseriesQuery, len(input.Queries))
+		for i, q := range input.Queries {
+			queries[i] = datadog.TimeseriesQuery{
+				Name:       q.Name,
+				DataSource: q.DataSource,
+				Query:      q.Query,
+				Aggregator: q.Aggregator,
+				Metric:     q.Metric,
+				GroupBy:    q.GroupBy,
+			}
+			if err := queries[i].Validate(); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		strategy, err := parseDownsample(input.Downsample)
+		if err != nil {
+			return nil, nil, err
+		}
+		sortBy, ascending, err := parseSort(input.SortBy, input.SortOrder)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		tr, err := r.timeRange("query_timeseries", input.From, input.To, input.Timezone)
+		if err != nil {
+			return nil, nil, err
+		}
+		var interval time.Duration
+		if input.Interval != "" {
+			if interval, err = parseInterval(input.Interval); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		result, err := client.QueryTimeseries(ctx, queries, input.Formulas, tr.From, tr.To, interval)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		summarizeSeries(result.Series, sortBy, ascending)
+
+		// Apply pagination limits
+		maxSeries := input.MaxSeries
+		if maxSeries <= 0 {
+			maxSeries = r.limits.MaxSeries
+		}
+		maxDataPoints := input.MaxDataPoints
+		if maxDataPoints <= 0 {
+			maxDataPoints = r.limits.MaxDataPoints
+		}
+		totalSeries := len(result.Series)
+		var truncatedSeries, downsampled bool
+		result.Series, truncatedSeries, downsampled = limitSeries(result.Series, maxSeries, maxDataPoints, strategy)
+		if downsampled {
+			result.Downsample = strategy
+		}
+
+		text.Printf("Queries:\n")
+		for _, q := range queries {
+			source := q.DataSource
+			if source == "" {
+				source = "metrics"
+			}
+			text.Printf("  %s (%s): %s", q.Name, source, q.Query)
+			if !q.Events() && q.Aggregator != "" {
+				text.Printf(" as %s", q.MetricQuery())
+			}
+			if q.Events() {
+				compute := q.Aggregator
+				if compute == "" {
+					compute = "count"
+				}
+				if q.Metric != "" {
+					compute += " of " + q.Metric
+				}
+				text.Printf(" [%s", compute)
+				if len(q.GroupBy) > 0 {
+					text.Printf(" by %s", strings.Join(q.GroupBy, ", "))
+				}
+				text.Printf("]")
+			}
+			text.Printf("\n")
+		}
+		if len(input.Formulas) > 0 {
+			text.Printf("Formulas: %s\n", strings.Join(input.Formulas, "; "))
+		}
+		text.Printf("Time Range: %s\n", tr)
+		if result.Interval > 0 {
+			text.Printf("Interval: %s\n", formatDuration(time.Duration(result.Interval)*time.Second))
+		}
+		text.Printf("Series Count: %d", len(result.Series))
+		if truncatedSeries {
+			text.Printf(" (truncated from %d, use max_series to see more)", totalSeries)
+		}
+		text.Printf("\n")
+		for _, w := range result.Warnings {
+			text.Printf("Warning: %s\n", w)
+		}
+		text.Printf("\n")
+
+		text.List(seriesList(result.Series, text, strategy))
+
+		// Add the resolved range and pagination info to result
+		result.From, result.To = tr.From, tr.To
+		result.TotalSeries = totalSeries
+		result.Truncated = truncatedSeries || downsampled && strategy == DownsampleTail
+
+		res, err := textResult(text, result)
+		if err != nil {
+			return nil, nil, err
+		}
+		return res, result, nil
+	})
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+)
+
+func TestQueryTimeseriesFormula(t *testing.T) {
+	backend := fake.New()
+	session := newTestSession(t, backend, Options{})
+
+	text, out := callTool[datadog.QueryTimeseriesResult](t, session, "query_timeseries", withRange(map[string]any{
+		"queries": []map[string]any{
+			{"name": "a", "query": "sum:trace.checkout.errors{service:checkout}.as_count()"},
+			{"name": "b", "query": "sum:trace.checkout.hits{service:checkout}.as_count()"},
+		},
+		"formulas": []string{"a / b * 100"},
+	}))
+	assertContains(t, text,
+		"a (metrics): sum:trace.checkout.errors{service:checkout}.as_count()",
+		"Formulas: a / b * 100",
+		"Series Count: 1",
+		"[1] a / b * 100 (3 data points, every ~30m)",
+	)
+
+	if len(out.Series) != 1 {
+		t.Fatalf("got %d series, want 1", len(out.Series))
+	}
+	want := []float64{2.0 / 400 * 100, 0, 3.0 / 450 * 100}
+	for i, p := range out.Series[0].DataPoints {
+		if math.Abs(p.Value-want[i]) > 1e-9 {
+			t.Errorf("point %d = %v, want %v", i, p.Value, want[i])
+		}
+	}
+}
+
+func TestQueryTimeseriesAggregator(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text, out := callTool[datadog.QueryTimeseriesResult](t, session, "query_timeseries", withRange(map[string]any{
+		"queries": []map[string]any{
+			{"name": "cpu", "query": "max:system.cpu.user{*}", "aggregator": "avg"},
+			{"name": "errors", "data_source": "logs", "query": "service:checkout status:error", "group_by": []string{"host"}},
+		},
+	}))
+	assertContains(t, text,
+		"cpu (metrics): max:system.cpu.user{*} as avg:system.cpu.user{*}",
+		"errors (logs): service:checkout status:error [count by host]",
+	)
+	if len(out.Series) != 1 || out.Series[0].Metric != "cpu" || len(out.Series[0].DataPoints) != 5 {
+		t.Errorf("series = %+v, want the 5 points of cpu", out.Series)
+	}
+}
+
+func TestQueryTimeseriesErrors(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	tests := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"no queries", map[string]any{"queries": []map[string]any{}}, "at least one query"},
+		{"unnamed query", map[string]any{"queries": []map[string]any{{"name": "", "query": "avg:system.cpu.user{*}"}}}, "has no name"},
+		{"unknown data source", map[string]any{"queries": []map[string]any{{"name": "a", "data_source": "traces", "query": "*"}}}, "invalid data source"},
+		{"unknown formula name", map[string]any{
+			"queries":  []map[string]any{{"name": "a", "query": "avg:system.cpu.user{*}"}},
+			"formulas": []string{"a + b"},
+		}, `unknown query "b"`},
+		{"invalid interval", map[string]any{
+			"queries":  []map[string]any{{"name": "a", "query": "avg:system.cpu.user{*}"}},
+			"interval": "often",
+		}, "often"},
+	}
+	for _, tt := range tests {
+		text := callToolError(t, session, "query_timeseries", withRange(tt.args))
+		if !strings.Contains(text, tt.want) {
+			t.Errorf("%s: error %q does not contain %q", tt.name, text, tt.want)
+		}
+	}
+}
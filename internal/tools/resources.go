@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/yosida95/uritemplate/v3"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// errResourceNotFound reports a resource URI naming no object.
+var errResourceNotFound = errors.New("resource not found")
+
+// resourcePageSize is the number of resources listed per resources/list page.
+const resourcePageSize = 100
+
+// resourceKind is a kind of Datadog object exposed as MCP resources, with
+// URIs such as datadog://dashboard/abc-123-def.
+type resourceKind struct {
+	// name is the host part of the kind's URIs, e.g. "dashboard".
+	name string
+	// param names the URI template variable identifying an object.
+	param string
+	// tool names the tool reading the same data. The kind is only exposed
+	// when the policy exposes that tool.
+	tool string
+	// lister names the tool listing the same objects, if not tool. The
+	// kind's resources are only listed when the policy exposes it too.
+	lister      string
+	description string
+	// read returns the object with the given ID, or nil if there is none.
+	read func(ctx context.Context, client datadog.Backend, id string) (any, error)
+	// list returns up to limit resources starting at offset, and whether
+	// more follow.
+	list func(ctx context.Context, r *registry, client datadog.Backend, offset, limit int) ([]*mcp.Resource, bool, error)
+}
+
+// resourceKinds lists the kinds of resources, in the order they are listed.
+var resourceKinds = []resourceKind{
+	{
+		name:        "dashboard",
+		param:       "id",
+		tool:        "get_dashboard",
+		description: "A Datadog dashboard with its widgets and template variables, by dashboard ID.",
+		read: func(ctx context.Context, client datadog.Backend, id string) (any, error) {
+			return client.GetDashboard(ctx, id)
+		},
+		list: func(ctx context.Context, r *registry, client datadog.Backend, offset, limit int) ([]*mcp.Resource, bool, error) {
+			result, err := client.ListDashboards(ctx, false, false, int64(limit), int64(offset))
+			if err != nil {
+				return nil, false, err
+			}
+			resources := make([]*mcp.Resource, len(result.Dashboards))
+			for i, d := range result.Dashboards {
+				resources[i] = &mcp.Resource{
+					URI:         resourceURI("dashboard", d.ID),
+					Name:        d.ID,
+					Title:       d.Title,
+					Description: d.Description,
+					MIMEType:    "application/json",
+				}
+			}
+			return resources, result.HasMore, nil
+		},
+	},
+	{
+		name:        "service",
+		param:       "name",
+		tool:        "get_apm_services",
+		description: "A service from the Datadog service catalog with its team, tier, contacts and links, by service name.",
+		read: func(ctx context.Context, client datadog.Backend, name string) (any, error) {
+			result, err := client.ListServices(ctx)
+			if err != nil {
+				return nil, err
+			}
+			i := slices.IndexFunc(result.Services, func(svc datadog.ServiceInfo) bool { return svc.Name == name })
+			if i < 0 {
+				return nil, nil
+			}
+			return result.Services[i], nil
+		},
+		list: func(ctx context.Context, r *registry, client datadog.Backend, offset, limit int) ([]*mcp.Resource, bool, error) {
+			result, err := client.ListServices(ctx)
+			if err != nil {
+				return nil, false, err
+			}
+			services := result.Services[min(offset, len(result.Services)):]
+			more := len(services) > limit
+			services = services[:min(limit, len(services))]
+			resources := make([]*mcp.Resource, len(services))
+			for i, svc := range services {
+				resources[i] = &mcp.Resource{
+					URI:         resourceURI("service", svc.Name),
+					Name:        svc.Name,
+					Description: svc.Description,
+					MIMEType:    "application/json",
+				}
+			}
+			return resources, more, nil
+		},
+	},
+	{
+		name:        "metric",
+		param:       "name",
+		tool:        "get_metric_metadata",
+		lister:      "list_metrics",
+		description: "The metadata of a Datadog metric, by metric name: its type, unit and description.",
+		read: func(ctx context.Context, client datadog.Backend, name string) (any, error) {
+			return client.GetMetricMetadata(ctx, name)
+		},
+		list: func(ctx context.Context, r *registry, client datadog.Backend, offset, limit int) ([]*mcp.Resource, bool, error) {
+			result, err := client.ListMetrics(ctx, time.Now().Add(-r.opts.lookback("list_metrics")), "", "")
+			if err != nil {
+				return nil, false, err
+			}
+			metrics := result.Metrics[min(offset, len(result.Metrics)):]
+			more := len(metrics) > limit
+			metrics = metrics[:min(limit, len(metrics))]
+			resources := make([]*mcp.Resource, len(metrics))
+			for i, name := range metrics {
+				resources[i] = &mcp.Resource{
+					URI:      resourceURI("metric", name),
+					Name:     name,
+					MIMEType: "application/json",
+				}
+			}
+			return resources, more, nil
+		},
+	},
+}
+
+// idTemplate escapes the ID part of resource URIs.
+var idTemplate = uritemplate.MustNew("{id}")
+
+// resourceURI returns the URI of the object of the given kind and ID in the
+// default org.
+func resourceURI(kind, id string) string {
+	id, _ = idTemplate.Expand(uritemplate.Values{"id": uritemplate.String(id)})
+	return "datadog://" + kind + "/" + id
+}
+
+// registerResources exposes each kind of resource whose tool is exposed, and
+// lists the default org's resources of those kinds whose lister is exposed
+// too, in pages.
+func registerResources(r *registry) {
+	if r.server == nil {
+		return
+	}
+
+	var kinds []resourceKind
+	for _, kind := range resourceKinds {
+		if !r.exposed(kind.tool) {
+			continue
+		}
+		if kind.lister == "" || r.exposed(kind.lister) {
+			kinds = append(kinds, kind)
+		}
+
+		template := fmt.Sprintf("datadog://%s/{%s}{?org}", kind.name, kind.param)
+		r.server.AddResourceTemplate(&mcp.ResourceTemplate{
+			Name:        kind.name,
+			URITemplate: template,
+			Description: kind.description + " Pass ?org=<name> to read from another org.",
+			MIMEType:    "application/json",
+		}, r.resourceHandler(kind, uritemplate.MustNew(template)))
+	}
+	if len(kinds) == 0 {
+		return
+	}
+
+	r.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			list, ok := req.(*mcp.ListResourcesRequest)
+			if method != "resources/list" || !ok {
+				return next(ctx, method, req)
+			}
+			return r.listResources(ctx, list, kinds)
+		}
+	})
+}
+
+// resourceHandler reads resources of kind, bounding each read by the timeout
+// of the kind's tool. Reads are instrumented like tool calls, as the
+// resources/read method.
+func (r *registry) resourceHandler(kind resourceKind, template *uritemplate.Template) mcp.ResourceHandler {
+	timeout := r.opts.timeout(kind.tool)
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		var result *mcp.ReadResourceResult
+		inv := invocation{name: "resources/read", session: sessionID(req.Session), args: resourceArgs("uri", uri), timeout: timeout}
+		err := r.instrument(ctx, inv, func(ctx context.Context, trace *datadog.Trace) (int, error) {
+			values := template.Match(uri)
+			id := values.Get(kind.param).String()
+			if id == "" {
+				return 0, errResourceNotFound
+			}
+			client, err := r.orgs.Get(values.Get("org").String())
+			if err != nil {
+				return 0, err
+			}
+
+			object, err := kind.read(ctx, client, id)
+			var apiErr *datadog.APIError
+			if errors.As(err, &apiErr) && apiErr.Kind == datadog.ErrorNotFound {
+				return 0, errResourceNotFound
+			}
+			if err != nil {
+				return 0, withHint(kind.tool, err)
+			}
+			if object == nil {
+				return 0, errResourceNotFound
+			}
+
+			data, err := json.MarshalIndent(object, "", "  ")
+			if err != nil {
+				return 0, err
+			}
+			result = &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{
+					{URI: uri, MIMEType: "application/json", Text: string(data)},
+				},
+			}
+			return len(data), nil
+		})
+		if errors.Is(err, errResourceNotFound) {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// resourceArgs returns the arguments of a resource method for the audit log.
+func resourceArgs(name, value string) json.RawMessage {
+	b, _ := json.Marshal(map[string]string{name: value})
+	return b
+}
+
+// listResources returns the page of the default org's resources starting at
+// cursor. A cursor names a kind and an offset into its resources, e.g.
+// "service:100"; each page holds resources of a single kind, and is bounded
+// by the timeout of the kind's tool. Listings are instrumented like tool
+// calls, as the resources/list method.
+func (r *registry) listResources(ctx context.Context, req *mcp.ListResourcesRequest, kinds []resourceKind) (*mcp.ListResourcesResult, error) {
+	var cursor string
+	if req.Params != nil {
+		cursor = req.Params.Cursor
+	}
+	k, offset := 0, 0
+	if cursor != "" {
+		name, n, _ := strings.Cut(cursor, ":")
+		k = slices.IndexFunc(kinds, func(kind resourceKind) bool { return kind.name == name })
+		var err error
+		offset, err = strconv.Atoi(n)
+		if k < 0 || err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", cursor)
+		}
+	}
+
+	kind := kinds[k]
+	var result *mcp.ListResourcesResult
+	inv := invocation{name: "resources/list", session: sessionID(req.Session), args: resourceArgs("cursor", cursor), timeout: r.opts.timeout(kind.tool)}
+	err := r.instrument(ctx, inv, func(ctx context.Context, trace *datadog.Trace) (int, error) {
+		client, err := r.orgs.Get("")
+		if err != nil {
+			return 0, err
+		}
+
+		resources, more, err := kind.list(ctx, r, client, offset, resourcePageSize)
+		if err != nil {
+			return 0, withHint(kind.tool, err)
+		}
+
+		result = &mcp.ListResourcesResult{Resources: resources}
+		switch {
+		case more:
+			result.NextCursor = fmt.Sprintf("%s:%d", kind.name, offset+len(resources))
+		case k+1 < len(kinds):
+			result.NextCursor = kinds[k+1].name + ":0"
+		}
+		b, _ := json.Marshal(result)
+		return len(b), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
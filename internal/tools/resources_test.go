@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+// slowBackend never answers dashboard listings before its context ends.
+type slowBackend struct {
+	*fake.Backend
+}
+
+func (b slowBackend) ListDashboards(ctx context.Context, filterShared, filterDeleted bool, limit, start int64) (*datadog.ListDashboardsResult, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestReadResource(t *testing.T) {
+	var log bytes.Buffer
+	session := newTestSession(t, fake.New(), Options{Audit: slog.New(slog.NewJSONHandler(&log, nil))})
+	reads := testutil.ToFloat64(telemetry.ToolCalls.WithLabelValues("resources/read", "ok"))
+
+	res, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "datadog://dashboard/abc-123-def"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dashboard datadog.Dashboard
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &dashboard); err != nil {
+		t.Fatal(err)
+	}
+	if dashboard.Title != "Checkout Overview" {
+		t.Errorf("title = %q, want Checkout Overview", dashboard.Title)
+	}
+
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "datadog://dashboard/nope"}); err == nil {
+		t.Error("reading a missing dashboard succeeded")
+	}
+
+	if got := testutil.ToFloat64(telemetry.ToolCalls.WithLabelValues("resources/read", "ok")) - reads; got != 1 {
+		t.Errorf("counted %v successful reads, want 1", got)
+	}
+	entries := auditEntries(t, &log)
+	if len(entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(entries))
+	}
+	args, _ := entries[0]["arguments"].(map[string]any)
+	if entries[0]["tool"] != "resources/read" || args["uri"] != "datadog://dashboard/abc-123-def" || entries[0]["result_bytes"].(float64) == 0 {
+		t.Errorf("audit entry = %v, want a read of datadog://dashboard/abc-123-def", entries[0])
+	}
+	if entries[1]["error_class"] != "not_found" {
+		t.Errorf("audit entry = %v, want a not_found error", entries[1])
+	}
+}
+
+func TestListResources(t *testing.T) {
+	var log bytes.Buffer
+	session := newTestSession(t, fake.New(), Options{Audit: slog.New(slog.NewJSONHandler(&log, nil))})
+
+	var uris []string
+	for res, err := range session.Resources(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		uris = append(uris, res.URI)
+	}
+	for _, want := range []string{"datadog://dashboard/abc-123-def", "datadog://service/payments", "datadog://metric/system.cpu.user"} {
+		if !strings.Contains(strings.Join(uris, " "), want) {
+			t.Errorf("resources %v do not include %s", uris, want)
+		}
+	}
+
+	entries := auditEntries(t, &log)
+	if len(entries) != 3 || entries[0]["tool"] != "resources/list" {
+		t.Errorf("audit entries = %v, want one resources/list entry per kind", entries)
+	}
+}
+
+func TestListResourcesPolicy(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{Policy: Policy{Disabled: []string{"list_metrics"}}})
+
+	for res, err := range session.Resources(context.Background(), nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(res.URI, "datadog://metric/") {
+			t.Errorf("listed %s with list_metrics disabled", res.URI)
+		}
+	}
+	if _, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "datadog://metric/system.cpu.user"}); err != nil {
+		t.Errorf("reading a metric with get_metric_metadata exposed: %v", err)
+	}
+}
+
+func TestListResourcesTimeout(t *testing.T) {
+	session := newTestSession(t, slowBackend{fake.New()}, Options{Timeouts: map[string]time.Duration{"get_dashboard": 50 * time.Millisecond}})
+
+	start := time.Now()
+	_, err := session.ListResources(context.Background(), nil)
+	if err == nil {
+		t.Fatal("listing resources succeeded, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("listing returned after %s, want about the tool timeout", elapsed)
+	}
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/render"
+)
+
+// seriesStatistics lists the statistics series can be sorted by.
+var seriesStatistics = []string{"min", "max", "mean", "last", "p50", "p95", "slope", "peak_at"}
+
+// trendThreshold is how much the fitted line of a series must change over
+// the series, relative to its mean, for the series to be rising or falling.
+const trendThreshold = 0.05
+
+// seriesStats summarizes points, or returns nil when there are none. Points
+// must be in time order.
+func seriesStats(points []datadog.MetricPoint) *datadog.SeriesStats {
+	if len(points) == 0 {
+		return nil
+	}
+
+	st := &datadog.SeriesStats{
+		Min:    points[0].Value,
+		Max:    points[0].Value,
+		Last:   points[len(points)-1].Value,
+		PeakAt: points[0].Timestamp,
+	}
+	values := make([]float64, len(points))
+	var sum float64
+	for i, p := range points {
+		values[i] = p.Value
+		sum += p.Value
+		st.Min = min(st.Min, p.Value)
+		if p.Value > st.Max {
+			st.Max, st.PeakAt = p.Value, p.Timestamp
+		}
+	}
+	st.Mean = sum / float64(len(points))
+
+	slices.Sort(values)
+	st.P50 = percentile(values, 50)
+	st.P95 = percentile(values, 95)
+
+	// Least squares over hours since the first point.
+	var sx, sy, sxx, sxy float64
+	for _, p := range points {
+		x := p.Timestamp.Sub(points[0].Timestamp).Hours()
+		sx += x
+		sy += p.Value
+		sxx += x * x
+		sxy += x * p.Value
+	}
+	n := float64(len(points))
+	if d := n*sxx - sx*sx; d != 0 {
+		st.Slope = (n*sxy - sx*sy) / d
+	}
+
+	st.Trend = "flat"
+	scale := math.Abs(st.Mean)
+	if scale == 0 {
+		scale = st.Max - st.Min
+	}
+	change := st.Slope * points[len(points)-1].Timestamp.Sub(points[0].Timestamp).Hours()
+	switch {
+	case scale == 0:
+	case change > trendThreshold*scale:
+		st.Trend = "rising"
+	case change < -trendThreshold*scale:
+		st.Trend = "falling"
+	}
+	return st
+}
+
+// percentile returns the p-th percentile of sorted values, by nearest rank.
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[max(rank, 1)-1]
+}
+
+// statistic returns the named statistic of st, with peak_at in seconds since
+// the epoch.
+func statistic(st *datadog.SeriesStats, name string) float64 {
+	switch name {
+	case "min":
+		return st.Min
+	case "max":
+		return st.Max
+	case "mean":
+		return st.Mean
+	case "last":
+		return st.Last
+	case "p50":
+		return st.P50
+	case "p95":
+		return st.P95
+	case "slope":
+		return st.Slope
+	default:
+		return float64(st.PeakAt.Unix())
+	}
+}
+
+// parseSort validates the sort_by and sort_order arguments. An empty sort_by
+// keeps Datadog's order, and the order defaults to descending.
+func parseSort(by, order string) (string, bool, error) {
+	if by != "" && !slices.Contains(seriesStatistics, by) {
+		return "", false, fmt.Errorf("invalid sort_by %q: use one of %s", by, strings.Join(seriesStatistics, ", "))
+	}
+	switch order {
+	case "", "desc":
+		return by, false, nil
+	case "asc":
+		return by, true, nil
+	default:
+		return "", false, fmt.Errorf("invalid sort_order %q: use asc or desc", order)
+	}
+}
+
+// summarizeSeries sets the stats of each series, and sorts the series by the
+// named statistic unless by is empty. Series without points come last.
+func summarizeSeries(series []datadog.MetricSeries, by string, ascending bool) {
+	for i := range series {
+		series[i].Stats = seriesStats(series[i].DataPoints)
+	}
+	if by == "" {
+		return
+	}
+	slices.SortStableFunc(series, func(a, b datadog.MetricSeries) int {
+		switch {
+		case a.Stats == nil && b.Stats == nil:
+			return 0
+		case a.Stats == nil:
+			return 1
+		case b.Stats == nil:
+			return -1
+		case ascending:
+			return cmp.Compare(statistic(a.Stats, by), statistic(b.Stats, by))
+		default:
+			return cmp.Compare(statistic(b.Stats, by), statistic(a.Stats, by))
+		}
+	})
+}
+
+// formatStats formats the stats of a series for a summary line, rendering
+// values with vf.
+func formatStats(st *datadog.SeriesStats, detail render.Detail, vf valueFormat) string {
+	if detail == render.DetailBrief {
+		return fmt.Sprintf("mean=%s max=%s last=%s %s", vf.format(st.Mean), vf.format(st.Max), vf.format(st.Last), st.Trend)
+	}
+	slope := vf.format(st.Slope)
+	if st.Slope > 0 {
+		slope = "+" + slope
+	}
+	return fmt.Sprintf("min=%s max=%s mean=%s last=%s p50=%s p95=%s, %s (%s per hour), peak at %s",
+		vf.format(st.Min), vf.format(st.Max), vf.format(st.Mean), vf.format(st.Last), vf.format(st.P50), vf.format(st.P95),
+		st.Trend, slope, st.PeakAt.UTC().Format(time.RFC3339))
+}
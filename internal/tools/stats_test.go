@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// hourly returns one point an hour with the given values.
+func hourly(values ...float64) []datadog.MetricPoint {
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	points := make([]datadog.MetricPoint, len(values))
+	for i, v := range values {
+		points[i] = datadog.MetricPoint{Timestamp: start.Add(time.Duration(i) * time.Hour), Value: v}
+	}
+	return points
+}
+
+func TestSeriesStats(t *testing.T) {
+	st := seriesStats(hourly(10, 20, 30, 20))
+	want := datadog.SeriesStats{Min: 10, Max: 30, Mean: 20, Last: 20, P50: 20, P95: 30, Slope: 4, Trend: "rising", PeakAt: time.Date(2025, 1, 15, 2, 0, 0, 0, time.UTC)}
+	if st == nil || *st != want {
+		t.Errorf("seriesStats = %+v, want %+v", st, want)
+	}
+
+	tests := []struct {
+		values []float64
+		trend  string
+	}{
+		{[]float64{5, 5, 5}, "flat"},
+		{[]float64{30, 20, 10}, "falling"},
+		{[]float64{100, 101, 100, 102}, "flat"},
+		{[]float64{-1, 1}, "rising"},
+		{[]float64{0, 0}, "flat"},
+		{[]float64{7}, "flat"},
+	}
+	for _, tt := range tests {
+		if st := seriesStats(hourly(tt.values...)); st.Trend != tt.trend {
+			t.Errorf("trend of %v = %s, want %s", tt.values, st.Trend, tt.trend)
+		}
+	}
+
+	if st := seriesStats(nil); st != nil {
+		t.Errorf("seriesStats(nil) = %+v, want nil", st)
+	}
+}
+
+func TestSummarizeSeries(t *testing.T) {
+	series := []datadog.MetricSeries{
+		{Metric: "low", DataPoints: hourly(1, 2)},
+		{Metric: "empty"},
+		{Metric: "high", DataPoints: hourly(9, 3)},
+		{Metric: "mid", DataPoints: hourly(5, 5)},
+	}
+	names := func() []string {
+		var names []string
+		for _, s := range series {
+			names = append(names, s.Metric)
+		}
+		return names
+	}
+
+	summarizeSeries(series, "max", false)
+	if want := []string{"high", "mid", "low", "empty"}; !slices.Equal(names(), want) {
+		t.Errorf("by max, descending = %v, want %v", names(), want)
+	}
+	summarizeSeries(series, "last", true)
+	if want := []string{"low", "high", "mid", "empty"}; !slices.Equal(names(), want) {
+		t.Errorf("by last, ascending = %v, want %v", names(), want)
+	}
+	if series[0].Stats == nil || series[3].Stats != nil {
+		t.Errorf("stats = %+v, %+v; want stats only for series with points", series[0].Stats, series[3].Stats)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		by, order string
+		ascending bool
+		err       bool
+	}{
+		{"", "", false, false},
+		{"p95", "", false, false},
+		{"peak_at", "asc", true, false},
+		{"max", "desc", false, false},
+		{"median", "", false, true},
+		{"max", "up", false, true},
+	}
+	for _, tt := range tests {
+		by, ascending, err := parseSort(tt.by, tt.order)
+		if (err != nil) != tt.err || (err == nil && (by != tt.by || ascending != tt.ascending)) {
+			t.Errorf("parseSort(%q, %q) = %q, %t, %v", tt.by, tt.order, by, ascending, err)
+		}
+	}
+}
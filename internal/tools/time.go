@@ -2,39 +2,213 @@ package tools
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+
+	// Embed the time zone database so the timezone argument works on hosts
+	// and containers without one.
+	_ "time/tzdata"
 )
 
-// parseTime parses time strings in RFC3339 format or relative format like "now-1h".
-func parseTime(s string) (time.Time, error) {
-	if s == "" || s == "now" {
-		return time.Now(), nil
+// TimeRange represents a time range.
+type TimeRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// String formats the range for a text summary.
+func (tr TimeRange) String() string {
+	return fmt.Sprintf("%s to %s", tr.From.Format(time.RFC3339), tr.To.Format(time.RFC3339))
+}
+
+// timeRange resolves the from, to and timezone arguments of the named tool
+// into an absolute range. An empty to means now, and an empty from means the
+// tool's lookback before to.
+func (r *registry) timeRange(tool, from, to, timezone string) (TimeRange, error) {
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("invalid timezone %q: use an IANA name such as Europe/Paris, or UTC", timezone)
+		}
+	}
+	now := time.Now().In(loc)
+
+	tr := TimeRange{To: now}
+	if to != "" {
+		t, err := parseTime(to, now)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("invalid 'to' time: %w", err)
+		}
+		tr.To = t
+	}
+	tr.From = tr.To.Add(-r.opts.lookback(tool))
+	if from != "" {
+		t, err := parseTime(from, now)
+		if err != nil {
+			return TimeRange{}, fmt.Errorf("invalid 'from' time: %w", err)
+		}
+		tr.From = t
+	}
+
+	if !tr.From.Before(tr.To) {
+		return TimeRange{}, fmt.Errorf("'from' time (%s) must be before 'to' time (%s)", tr.From.Format(time.RFC3339), tr.To.Format(time.RFC3339))
+	}
+	return tr, nil
+}
+
+// localLayouts lists the layouts of absolute times without a UTC offset,
+// which are read in the timezone of the request.
+var localLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// relativePattern matches the part of a Datadog-style relative time after
+// "now": offsets such as -1d or +2h, then an optional rounding such as /d.
+var relativePattern = regexp.MustCompile(`^((?:[+-]\d+(?:mo|[smhdwy]))*)(?:/(mo|[smhdwy]))?$`)
+
+// offsetPattern matches a single offset of a relative time.
+var offsetPattern = regexp.MustCompile(`([+-])(\d+)(mo|[smhdwy])`)
+
+// parseTime parses a time the way Datadog does, relative to now and in now's
+// location:
+//   - now, today (midnight) and yesterday (midnight the day before), in
+//     any case
+//   - now with offsets and rounding, e.g. now-15m, now-1d, now-1w/w, now/d,
+//     in units of s, m, h, d, w, mo and y; now-1h30m also works
+//   - epoch timestamps in seconds or milliseconds
+//   - RFC3339, or a date and time without UTC offset such as 2024-01-15,
+//     2024-01-15 10:00 or 2024-01-15T10:00:00
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	switch strings.ToLower(s) {
+	case "now":
+		return now, nil
+	case "today":
+		return truncateTime(now, "d"), nil
+	case "yesterday":
+		return truncateTime(now, "d").AddDate(0, 0, -1), nil
+	}
+
+	if len(s) >= 3 && strings.EqualFold(s[:3], "now") {
+		return parseRelative(s, s[3:], now)
+	}
+
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n >= 0 {
+		// Twelve digits are past the year 5000 in seconds, so longer
+		// timestamps are milliseconds.
+		if len(s) >= 12 {
+			return time.UnixMilli(n).In(now.Location()), nil
+		}
+		return time.Unix(n, 0).In(now.Location()), nil
 	}
 
-	// Try RFC3339 first
-	if t, err := time.Parse(time.RFC3339, s); err == nil {
-		return t, nil
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.In(now.Location()), nil
+	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q: use now, a relative time such as now-15m, now-1d or now/d, today, yesterday, an epoch timestamp, RFC3339 or 2006-01-02 15:04:05", s)
+}
+
+// parseRelative parses rel, the part of the relative time s after "now".
+func parseRelative(s, rel string, now time.Time) (time.Time, error) {
+	m := relativePattern.FindStringSubmatch(rel)
+	if m == nil {
+		// Go durations such as -1h30m predate Datadog-style offsets.
+		d, err := time.ParseDuration(rel)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid relative time %q: use offsets such as now-15m, now-2h, now-1d, now-1w or now-1mo, optionally rounded as in now-1d/d", s)
+		}
+		return now.Add(d), nil
 	}
 
-	// Try relative time format (now-1h, now-15m, etc.)
-	if len(s) > 3 && s[:3] == "now" {
-		offset := s[3:]
-		d, err := time.ParseDuration(offset)
+	t := now
+	for _, offset := range offsetPattern.FindAllStringSubmatch(m[1], -1) {
+		n, err := strconv.Atoi(offset[2])
 		if err != nil {
-			return time.Time{}, fmt.Errorf("invalid relative time format: %s", s)
+			return time.Time{}, fmt.Errorf("invalid relative time %q: %w", s, err)
 		}
-		return time.Now().Add(d), nil
+		if offset[1] == "-" {
+			n = -n
+		}
+		t = addTime(t, n, offset[3])
+	}
+	if m[2] != "" {
+		t = truncateTime(t, m[2])
 	}
+	return t, nil
+}
 
-	// Try date-time format without timezone
-	if t, err := time.Parse("2006-01-02T15:04:05", s); err == nil {
-		return t, nil
+// addTime adds n units to t. Days, weeks, months and years follow the
+// calendar of t's location.
+func addTime(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "s":
+		return t.Add(time.Duration(n) * time.Second)
+	case "m":
+		return t.Add(time.Duration(n) * time.Minute)
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "mo":
+		return t.AddDate(0, n, 0)
+	default:
+		return t.AddDate(n, 0, 0)
 	}
+}
 
-	// Try date only format
-	if t, err := time.Parse("2006-01-02", s); err == nil {
-		return t, nil
+// truncateTime rounds t down to the start of its unit in t's location.
+// Weeks start on Monday.
+func truncateTime(t time.Time, unit string) time.Time {
+	y, mo, d := t.Date()
+	loc := t.Location()
+	switch unit {
+	case "s":
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, loc)
+	case "m":
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case "h":
+		return time.Date(y, mo, d, t.Hour(), 0, 0, 0, loc)
+	case "d":
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc)
+	case "w":
+		return time.Date(y, mo, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case "mo":
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc)
 	}
+}
+
+// intervalPattern matches an interval in Datadog units, e.g. 30s, 5m or 1d.
+var intervalPattern = regexp.MustCompile(`^(\d+)([smhdw])$`)
 
-	return time.Time{}, fmt.Errorf("unrecognized time format: %s", s)
+// parseInterval parses the time between points of a series, e.g. 1m, 1h or
+// 1d. Go durations such as 1h30m also work.
+func parseInterval(s string) (time.Duration, error) {
+	d, err := time.ParseDuration(s)
+	if m := intervalPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		epoch := time.Unix(0, 0).UTC()
+		d, err = addTime(epoch, n, m[2]).Sub(epoch), nil
+	}
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("invalid interval %q: use a duration of at least 1s such as 30s, 5m, 1h or 1d", s)
+	}
+	return d, nil
 }
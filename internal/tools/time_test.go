@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A Wednesday.
+	now := time.Date(2025, 1, 15, 13, 45, 30, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		loc  *time.Location
+		want string
+	}{
+		{"now", time.UTC, "2025-01-15T13:45:30Z"},
+		{" now ", time.UTC, "2025-01-15T13:45:30Z"},
+		{"NOW", time.UTC, "2025-01-15T13:45:30Z"},
+		{"now-15m", time.UTC, "2025-01-15T13:30:30Z"},
+		{"now-1d", time.UTC, "2025-01-14T13:45:30Z"},
+		{"NOW-1d", time.UTC, "2025-01-14T13:45:30Z"},
+		{"Now/d", time.UTC, "2025-01-15T00:00:00Z"},
+		{"now+2h", time.UTC, "2025-01-15T15:45:30Z"},
+		{"now-1w/w", time.UTC, "2025-01-06T00:00:00Z"},
+		{"now/d", time.UTC, "2025-01-15T00:00:00Z"},
+		{"now-1d/d", time.UTC, "2025-01-14T00:00:00Z"},
+		{"now-15m/h", time.UTC, "2025-01-15T13:00:00Z"},
+		{"now-1mo", time.UTC, "2024-12-15T13:45:30Z"},
+		{"now/mo", time.UTC, "2025-01-01T00:00:00Z"},
+		{"now-1y/y", time.UTC, "2024-01-01T00:00:00Z"},
+		{"now-1d-12h", time.UTC, "2025-01-14T01:45:30Z"},
+		{"now-1h30m", time.UTC, "2025-01-15T12:15:30Z"},
+		{"1736899200", time.UTC, "2025-01-15T00:00:00Z"},
+		{"1736899200000", time.UTC, "2025-01-15T00:00:00Z"},
+		{"1736899200500", time.UTC, "2025-01-15T00:00:00.5Z"},
+		{"today", time.UTC, "2025-01-15T00:00:00Z"},
+		{"Yesterday", time.UTC, "2025-01-14T00:00:00Z"},
+		{"today", paris, "2025-01-15T00:00:00+01:00"},
+		{"yesterday", paris, "2025-01-14T00:00:00+01:00"},
+		{"now/d", paris, "2025-01-15T00:00:00+01:00"},
+		{"2025-01-15T10:00:00Z", time.UTC, "2025-01-15T10:00:00Z"},
+		{"2025-01-15T10:00:00+02:00", paris, "2025-01-15T08:00:00Z"},
+		{"2025-01-15 10:00", time.UTC, "2025-01-15T10:00:00Z"},
+		{"2025-01-15 10:00", paris, "2025-01-15T10:00:00+01:00"},
+		{"2025-01-15", paris, "2025-01-15T00:00:00+01:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.loc.String()+"/"+tt.in, func(t *testing.T) {
+			want, err := time.Parse(time.RFC3339Nano, tt.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseTime(tt.in, now.In(tt.loc))
+			if err != nil {
+				t.Fatalf("parseTime(%q) failed: %v", tt.in, err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("parseTime(%q) = %s, want %s", tt.in, got.Format(time.RFC3339Nano), tt.want)
+			}
+			if got.Location() != tt.loc {
+				t.Errorf("parseTime(%q) is in %s, want %s", tt.in, got.Location(), tt.loc)
+			}
+		})
+	}
+}
+
+func TestParseTimeErrors(t *testing.T) {
+	now := time.Date(2025, 1, 15, 13, 45, 30, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", "unrecognized time"},
+		{"tomorrow", "unrecognized time"},
+		{"-1736899200", "unrecognized time"},
+		{"2025-13-01", "unrecognized time"},
+		{"now-", "invalid relative time"},
+		{"now-1x", "invalid relative time"},
+		{"now/q", "invalid relative time"},
+		{"now-1d/", "invalid relative time"},
+	}
+	for _, tt := range tests {
+		if _, err := parseTime(tt.in, now); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseTime(%q) = %v, want an error containing %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestTimeRange(t *testing.T) {
+	r := &registry{opts: Options{Lookbacks: map[string]time.Duration{"query_metrics": 2 * time.Hour}}}
+
+	tr, err := r.timeRange("query_metrics", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := tr.To.Sub(tr.From); d != 2*time.Hour {
+		t.Errorf("default range spans %s, want the 2h lookback", d)
+	}
+	if time.Since(tr.To) > time.Minute {
+		t.Errorf("default range ends at %s, want now", tr.To)
+	}
+
+	tr, err = r.timeRange("query_metrics", "2025-01-15 10:00", "2025-01-15 11:00", "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2025-01-15T10:00:00+09:00 to 2025-01-15T11:00:00+09:00"; tr.String() != want {
+		t.Errorf("range = %s, want %s", tr, want)
+	}
+
+	invalid := []struct {
+		from, to, timezone string
+		want               string
+	}{
+		{"2025-01-15 11:00", "2025-01-15 10:00", "", "'from' time (2025-01-15T11:00:00Z) must be before 'to' time (2025-01-15T10:00:00Z)"},
+		{"now", "now", "", "must be before"},
+		{"soon", "", "", "invalid 'from' time: unrecognized time \"soon\""},
+		{"", "now-1q", "", "invalid 'to' time: invalid relative time \"now-1q\""},
+		{"", "", "Mars/Olympus", "invalid timezone \"Mars/Olympus\""},
+	}
+	for _, tt := range invalid {
+		_, err := r.timeRange("query_metrics", tt.from, tt.to, tt.timezone)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("timeRange(%q, %q, %q) = %v, want an error containing %q", tt.from, tt.to, tt.timezone, err, tt.want)
+		}
+	}
+}
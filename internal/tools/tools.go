@@ -1,18 +1,306 @@
 package tools
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/pedrospdc/datadog-mcp/internal/config"
 	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
 )
 
-// RegisterAll registers all Datadog tools with the MCP server.
-func RegisterAll(server *mcp.Server, client *datadog.Client) {
-	registerQueryMetrics(server, client)
-	registerListMetrics(server, client)
-	registerGetAPMServices(server, client)
-	registerQuerySpans(server, client)
-	registerQueryAPMStats(server, client)
-	registerListDashboards(server, client)
-	registerGetDashboard(server, client)
+// queueNoticeThreshold is the time spent queued behind the concurrency limits
+// above which a tool result mentions the wait.
+const queueNoticeThreshold = 100 * time.Millisecond
+
+// Options controls how tools are registered and executed.
+type Options struct {
+	// DefaultTimeout bounds every tool call. Zero means no timeout.
+	DefaultTimeout time.Duration
+	// Timeouts overrides DefaultTimeout for individual tools, keyed by tool name.
+	Timeouts map[string]time.Duration
+	// Lookbacks overrides how far back time-bound tools look when the caller
+	// passes no start time, keyed by tool name.
+	Lookbacks map[string]time.Duration
+	// Limits bounds tool output. Zero fields fall back to config.DefaultLimits.
+	Limits config.Limits
+	// Policy decides which tools are registered.
+	Policy Policy
+	// Audit, if set, receives a structured entry for every tool call.
+	Audit *slog.Logger
+}
+
+// timeout returns the timeout that applies to the named tool.
+func (o Options) timeout(name string) time.Duration {
+	if d, ok := o.Timeouts[name]; ok {
+		return d
+	}
+	return o.DefaultTimeout
+}
+
+// lookback returns how far back the named tool looks by default.
+func (o Options) lookback(name string) time.Duration {
+	if d, ok := o.Lookbacks[name]; ok {
+		return d
+	}
+	return config.DefaultLookbacks[name]
+}
+
+// limits returns the output limits, with unset fields filled from the defaults.
+func (o Options) limits() config.Limits {
+	l := o.Limits
+	d := config.DefaultLimits
+	if l.MaxSeries <= 0 {
+		l.MaxSeries = d.MaxSeries
+	}
+	if l.MaxDataPoints <= 0 {
+		l.MaxDataPoints = d.MaxDataPoints
+	}
+	if l.MaxSpans <= 0 {
+		l.MaxSpans = d.MaxSpans
+	}
+	if l.MaxDashboards <= 0 {
+		l.MaxDashboards = d.MaxDashboards
+	}
+	if l.MaxMetrics <= 0 {
+		l.MaxMetrics = d.MaxMetrics
+	}
+	if l.MaxOutputChars <= 0 {
+		l.MaxOutputChars = d.MaxOutputChars
+	}
+	return l
+}
+
+// registry carries the server and options shared by every tool registration.
+// A registry without a server only collects tool names.
+type registry struct {
+	server *mcp.Server
+	orgs   *datadog.Orgs
+	opts   Options
+	limits config.Limits
+	names  []string
+	report []Registration
+}
+
+// registrations lists the functions registering each tool.
+var registrations = []func(*registry){
+	registerQueryMetrics,
+	registerQueryTimeseries,
+	registerListMetrics,
+	registerGetMetricMetadata,
+	registerListMetricTags,
+	registerGetAPMServices,
+	registerQuerySpans,
+	registerQueryAPMStats,
+	registerListDashboards,
+	registerGetDashboard,
+}
+
+// toolScopes maps each tool to the application key scope it needs.
+var toolScopes = map[string]string{
+	"query_metrics":       datadog.ScopeTimeseriesQuery,
+	"query_timeseries":    datadog.ScopeTimeseriesQuery,
+	"list_metrics":        datadog.ScopeMetricsRead,
+	"get_metric_metadata": datadog.ScopeMetricsRead,
+	"list_metric_tags":    datadog.ScopeMetricsRead,
+	"get_apm_services":    datadog.ScopeServiceCatalogRead,
+	"query_spans":         datadog.ScopeAPMRead,
+	"query_apm_stats":     datadog.ScopeTimeseriesQuery,
+	"list_dashboards":     datadog.ScopeDashboardsRead,
+	"get_dashboard":       datadog.ScopeDashboardsRead,
+}
+
+// UsingScope returns the names of the tools that need the given application
+// key scope.
+func UsingScope(scope string) []string {
+	var names []string
+	for _, name := range Names() {
+		if toolScopes[name] == scope {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RegisterAll registers the Datadog tools allowed by opts.Policy with the MCP
+// server, and reports which tools were exposed. Each tool queries the org
+// named in its "org" argument, or the default org of orgs. Dashboards,
+// services and metrics are also exposed as resources, and investigation
+// playbooks as prompts, when the tools they rely on are exposed.
+func RegisterAll(server *mcp.Server, orgs *datadog.Orgs, opts Options) []Registration {
+	r := &registry{server: server, orgs: orgs, opts: opts, limits: opts.limits()}
+	for _, register := range registrations {
+		register(r)
+	}
+	registerResources(r)
+	registerPrompts(r)
+	return r.report
+}
+
+// Names returns the names of all available tools.
+func Names() []string {
+	r := &registry{orgs: datadog.NewOrgs("", nil)}
+	for _, register := range registrations {
+		register(r)
+	}
+	return r.names
+}
+
+// Preview reports which tools policy would expose, without registering them.
+func Preview(policy Policy) []Registration {
+	server := mcp.NewServer(&mcp.Implementation{Name: "preview"}, nil)
+	return RegisterAll(server, datadog.NewOrgs("", nil), Options{Policy: policy})
+}
+
+// CheckNames returns an error naming any entry of names that is not a tool.
+func CheckNames(names []string) error {
+	known := Names()
+	var unknown []string
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown tool(s) %s; available tools: %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// exposed reports whether the named tool was registered with the server.
+func (r *registry) exposed(tool string) bool {
+	i := slices.IndexFunc(r.report, func(reg Registration) bool { return reg.Name == tool })
+	return i >= 0 && r.report[i].Exposed
+}
+
+// addTool registers handler for tool, bounding each call by the tool's
+// configured timeout. The handler's context is cancelled when the client
+// cancels the request or the timeout expires, aborting any upstream calls.
+// Every call is counted and timed in the server's metrics, and audited when
+// an audit logger is configured.
+func addTool[In, Out any](r *registry, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, Out]) {
+	r.names = append(r.names, tool.Name)
+	if r.server == nil {
+		return
+	}
+
+	allowed, reason := r.opts.Policy.check(tool)
+	r.report = append(r.report, Registration{Name: tool.Name, Exposed: allowed, Reason: reason})
+	if !allowed {
+		return
+	}
+
+	timeout := r.opts.timeout(tool.Name)
+
+	// Let the model know which orgs it can pick from.
+	if names := r.orgs.Names(); len(names) > 1 {
+		t := *tool
+		t.Description += fmt.Sprintf(" Available orgs: %s (default: %s).", strings.Join(names, ", "), r.orgs.Default())
+		tool = &t
+	}
+
+	mcp.AddTool(r.server, tool, func(ctx context.Context, req *mcp.CallToolRequest, input In) (*mcp.CallToolResult, Out, error) {
+		var (
+			result *mcp.CallToolResult
+			output Out
+			zero   Out
+		)
+		inv := invocation{name: tool.Name, session: sessionID(req.Session), args: req.Params.Arguments, timeout: timeout}
+		err := r.instrument(ctx, inv, func(ctx context.Context, trace *datadog.Trace) (int, error) {
+			var err error
+			result, output, err = handler(ctx, req, input)
+
+			// Some tools tolerate partial upstream failures, so check the
+			// context itself rather than relying on the handler to surface
+			// the deadline.
+			if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return 0, fmt.Errorf("%s timed out after %s; narrow the time range or query, or raise the timeout for this tool", tool.Name, timeout)
+			}
+			if err != nil {
+				return 0, withHint(tool.Name, err)
+			}
+
+			// Tell the caller when the concurrency limits slowed the call down.
+			if queued := trace.Queued(); queued >= queueNoticeThreshold && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{
+					Text: fmt.Sprintf("Note: Datadog requests waited %s for a free slot under the server's concurrency limits.", queued.Round(time.Millisecond)),
+				})
+			}
+			return resultSize(result, output), nil
+		})
+		if err != nil {
+			return nil, zero, err
+		}
+		return result, output, nil
+	})
+}
+
+// invocation describes one call to a tool, or to a resource method.
+type invocation struct {
+	// name is the tool name, or the MCP method for resources.
+	name    string
+	session string
+	args    json.RawMessage
+	timeout time.Duration
+}
+
+// instrument runs handle for inv, bounded by inv's timeout and attributed to
+// its session. The call is traced, counted and timed in the server's
+// metrics, and audited when an audit logger is configured. handle returns
+// the size of its result in bytes.
+func (r *registry) instrument(ctx context.Context, inv invocation, handle func(ctx context.Context, trace *datadog.Trace) (int, error)) error {
+	if inv.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, inv.timeout)
+		defer cancel()
+	}
+
+	var trace *datadog.Trace
+	ctx, trace = datadog.WithTrace(ctx)
+	ctx = datadog.WithSession(ctx, inv.session)
+	start := time.Now()
+
+	telemetry.ToolsInFlight.WithLabelValues(inv.name).Inc()
+	defer telemetry.ToolsInFlight.WithLabelValues(inv.name).Dec()
+	size, err := handle(ctx, trace)
+
+	outcome := "ok"
+	if err != nil {
+		outcome = errorClass(ctx, err, trace.Calls())
+	}
+	telemetry.ToolCalls.WithLabelValues(inv.name, outcome).Inc()
+	telemetry.ToolDuration.WithLabelValues(inv.name).Observe(time.Since(start).Seconds())
+
+	if r.opts.Audit != nil {
+		record := auditRecord{
+			tool:     inv.name,
+			session:  inv.session,
+			args:     inv.args,
+			start:    start,
+			upstream: trace.Calls(),
+			queued:   trace.Queued(),
+			size:     size,
+			err:      err,
+			ctx:      ctx,
+		}
+		record.log(r.opts.Audit)
+	}
+	return err
+}
+
+// sessionID returns the ID of session, which is nil for calls made before a
+// session was established.
+func sessionID(session *mcp.ServerSession) string {
+	if session == nil {
+		return ""
+	}
+	return session.ID()
 }
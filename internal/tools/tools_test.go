@@ -0,0 +1,169 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+	"github.com/pedrospdc/datadog-mcp/internal/datadog/fake"
+	"github.com/pedrospdc/datadog-mcp/internal/telemetry"
+)
+
+// testRange is a fixed time range, so that results from the fake backend do
+// not depend on when the tests run.
+var testRange = map[string]any{"from": "2025-01-15T12:00:00Z", "to": "2025-01-15T13:00:00Z"}
+
+// withRange returns args with the test time range added.
+func withRange(args map[string]any) map[string]any {
+	for k, v := range testRange {
+		args[k] = v
+	}
+	return args
+}
+
+// newTestSession registers the tools with backend as the default org and
+// connects an in-process MCP client to them.
+func newTestSession(t *testing.T, backend datadog.Backend, opts Options) *mcp.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "datadog-mcp", Version: "test"}, nil)
+	RegisterAll(server, datadog.NewOrgs("default", map[string]datadog.Backend{"default": backend}), opts)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+// call calls the named tool and returns its result and text.
+func call(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (*mcp.CallToolResult, string) {
+	t.Helper()
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	var text []string
+	for _, c := range res.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			text = append(text, tc.Text)
+		}
+	}
+	return res, strings.Join(text, "\n")
+}
+
+// callTool calls the named tool, which must succeed, and returns its text
+// and structured output.
+func callTool[Out any](t *testing.T, session *mcp.ClientSession, name string, args map[string]any) (string, *Out) {
+	t.Helper()
+	res, text := call(t, session, name, args)
+	if res.IsError {
+		t.Fatalf("%s failed: %s", name, text)
+	}
+	data, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(Out)
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatalf("%s: decoding structured output: %v", name, err)
+	}
+	return text, out
+}
+
+// callToolError calls the named tool, which must fail, and returns its error
+// text.
+func callToolError(t *testing.T, session *mcp.ClientSession, name string, args map[string]any) string {
+	t.Helper()
+	res, text := call(t, session, name, args)
+	if !res.IsError {
+		t.Fatalf("%s succeeded, want an error:\n%s", name, text)
+	}
+	return text
+}
+
+// assertContains fails unless text contains each of want.
+func assertContains(t *testing.T, text string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(text, w) {
+			t.Errorf("text does not contain %q:\n%s", w, text)
+		}
+	}
+}
+
+func TestToolTimeout(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{Timeouts: map[string]time.Duration{"query_spans": time.Nanosecond}})
+
+	text := callToolError(t, session, "query_spans", map[string]any{"query": "service:checkout"})
+	assertContains(t, text, "query_spans timed out after 1ns")
+}
+
+func TestToolMetrics(t *testing.T) {
+	backend := fake.New()
+	backend.Errors = map[string]error{"ListServices": errors.New("boom")}
+	session := newTestSession(t, backend, Options{})
+	ok := telemetry.ToolCalls.WithLabelValues("query_spans", "ok")
+	failed := telemetry.ToolCalls.WithLabelValues("get_apm_services", "tool")
+	okBefore, failedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(failed)
+
+	callTool[any](t, session, "query_spans", map[string]any{"query": "service:checkout"})
+	callToolError(t, session, "get_apm_services", map[string]any{})
+
+	if got := testutil.ToFloat64(ok) - okBefore; got != 1 {
+		t.Errorf("counted %v successful query_spans calls, want 1", got)
+	}
+	if got := testutil.ToFloat64(failed) - failedBefore; got != 1 {
+		t.Errorf("counted %v failed get_apm_services calls, want 1", got)
+	}
+	if n := testutil.ToFloat64(telemetry.ToolsInFlight.WithLabelValues("query_spans")); n != 0 {
+		t.Errorf("%v query_spans calls in flight after returning, want 0", n)
+	}
+}
+
+func TestRegisterAllPolicy(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{Policy: Policy{Enabled: []string{"query_*", "list_*"}, Disabled: []string{"list_dashboards"}}})
+
+	res, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range res.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	want := []string{"list_metric_tags", "list_metrics", "query_apm_stats", "query_metrics", "query_spans", "query_timeseries"}
+	if !slices.Equal(names, want) {
+		t.Errorf("tools = %v, want %v", names, want)
+	}
+
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_dashboard", Arguments: map[string]any{"dashboard_id": "abc-123-def"}}); err == nil {
+		t.Error("calling a disabled tool succeeded")
+	}
+}
+
+func TestUnknownOrg(t *testing.T) {
+	session := newTestSession(t, fake.New(), Options{})
+
+	text := callToolError(t, session, "get_apm_services", map[string]any{"org": "eu1"})
+	assertContains(t, text, `unknown org "eu1"`, "available orgs: default")
+}
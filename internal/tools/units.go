@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pedrospdc/datadog-mcp/internal/datadog"
+)
+
+// displayUnit is a unit values are rendered in, worth size base units.
+type displayUnit struct {
+	symbol string
+	size   float64
+}
+
+// unitFamily converts the Datadog units of one quantity to a base unit, and
+// lists the units to render it in, smallest first.
+type unitFamily struct {
+	base    map[string]float64
+	display []displayUnit
+}
+
+var (
+	// timeUnits measures time in nanoseconds.
+	timeUnits = unitFamily{
+		base: map[string]float64{
+			"nanosecond": 1, "microsecond": 1e3, "millisecond": 1e6, "second": 1e9,
+			"minute": 60e9, "hour": 3600e9, "day": 86400e9, "week": 604800e9,
+		},
+		display: []displayUnit{{"ns", 1}, {"µs", 1e3}, {"ms", 1e6}, {"s", 1e9}, {"min", 60e9}, {"h", 3600e9}, {"d", 86400e9}},
+	}
+	// byteUnits measures data in bytes.
+	byteUnits = unitFamily{
+		base: map[string]float64{
+			"bit": 1.0 / 8, "byte": 1, "kibibyte": 1 << 10, "mebibyte": 1 << 20,
+			"gibibyte": 1 << 30, "tebibyte": 1 << 40, "pebibyte": 1 << 50,
+		},
+		display: []displayUnit{{"B", 1}, {"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40}, {"PiB", 1 << 50}},
+	}
+)
+
+// perUnitSymbols abbreviates the per units of rates.
+var perUnitSymbols = map[string]string{
+	"nanosecond": "ns", "microsecond": "µs", "millisecond": "ms", "second": "s",
+	"minute": "min", "hour": "h", "day": "d", "week": "w",
+}
+
+// valueFormat renders the values of a series in a readable unit.
+type valueFormat struct {
+	scale  float64
+	suffix string
+	// digits is the number of significant digits rendered.
+	digits int
+}
+
+// seriesFormat returns how to render values in unit per perUnit whose
+// magnitude is about magnitude: times and sizes in the largest unit the
+// magnitude reaches, e.g. nanoseconds as ms and bytes as MiB, percents with
+// %, and other units by name. Values without a unit are left bare.
+func seriesFormat(unit, perUnit string, magnitude float64) valueFormat {
+	f := valueFormat{scale: 1, digits: 6}
+	switch {
+	case unit == "":
+	case unit == "percent":
+		f.suffix = "%"
+	case timeUnits.base[unit] > 0:
+		f = timeUnits.format(unit, magnitude)
+	case byteUnits.base[unit] > 0:
+		f = byteUnits.format(unit, magnitude)
+	default:
+		f.suffix = " " + unit
+	}
+	if perUnit != "" {
+		symbol, ok := perUnitSymbols[perUnit]
+		if !ok {
+			symbol = perUnit
+		}
+		f.suffix += "/" + symbol
+	}
+	return f
+}
+
+// format returns how to render values in unit, a unit of the family.
+func (fam unitFamily) format(unit string, magnitude float64) valueFormat {
+	base := fam.base[unit]
+	d := fam.display[0]
+	for _, u := range fam.display {
+		if math.Abs(magnitude)*base >= u.size {
+			d = u
+		}
+	}
+	// Values scaled to a display unit mostly fall between 1 and 1000, so
+	// four digits keep them readable.
+	return valueFormat{scale: base / d.size, suffix: d.symbol, digits: 4}
+}
+
+// format renders v.
+func (f valueFormat) format(v float64) string {
+	return fmt.Sprintf("%.*g%s", f.digits, v*f.scale, f.suffix)
+}
+
+const (
+	// metadataLookups bounds the metric metadata lookups enrichSeries runs
+	// at once.
+	metadataLookups = 4
+	// metadataReserve is the time enrichSeries leaves before the call's
+	// deadline, so slow metadata never costs the query result itself.
+	metadataReserve = 2 * time.Second
+)
+
+// enrichSeries fills in the type, unit and per unit of series from the
+// metadata of their metrics, where the query response left them out. Each
+// metric is looked up once, a few at a time, and lookups stop
+// metadataReserve before ctx's deadline. Metadata is best effort: metrics
+// without any are left as they are, and enrichSeries returns a warning when
+// lookups fail otherwise or are skipped.
+func enrichSeries(ctx context.Context, client datadog.Backend, series []datadog.MetricSeries) []string {
+	if deadline, ok := ctx.Deadline(); ok {
+		if time.Until(deadline) < metadataReserve {
+			return []string{"types and units left out: the tool timeout is too close to look up metric metadata"}
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-metadataReserve))
+		defer cancel()
+	}
+
+	var metrics []string
+	for _, s := range series {
+		if !slices.Contains(metrics, s.Metric) {
+			metrics = append(metrics, s.Metric)
+		}
+	}
+	metadata := make(map[string]*datadog.MetricMetadata, len(metrics))
+	errs := make(map[string]error)
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	sem := make(chan struct{}, metadataLookups)
+	for _, metric := range metrics {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			md, err := client.GetMetricMetadata(ctx, metric)
+			mu.Lock()
+			defer mu.Unlock()
+			metadata[metric], errs[metric] = md, err
+		})
+	}
+	wg.Wait()
+
+	for i := range series {
+		s := &series[i]
+		md := metadata[s.Metric]
+		if md == nil {
+			continue
+		}
+		s.Type = md.Type
+		if s.Unit == "" {
+			s.Unit, s.PerUnit = md.Unit, md.PerUnit
+		}
+	}
+
+	var failed []string
+	var first error
+	for _, metric := range metrics {
+		var apiErr *datadog.APIError
+		err := errs[metric]
+		if err == nil || errors.As(err, &apiErr) && apiErr.Kind == datadog.ErrorNotFound {
+			continue
+		}
+		failed = append(failed, metric)
+		if first == nil {
+			first = err
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("types and units left out of %s: %v", strings.Join(failed, ", "), first)}
+}
@@ -0,0 +1,32 @@
+package tools
+
+import "testing"
+
+func TestSeriesFormat(t *testing.T) {
+	tests := []struct {
+		unit, perUnit string
+		magnitude     float64
+		value         float64
+		want          string
+	}{
+		{"", "", 1234.5678, 1234.5678, "1234.57"},
+		{"percent", "", 50, 42.5, "42.5%"},
+		{"nanosecond", "", 130e6, 130e6, "130ms"},
+		{"nanosecond", "", 500, 512, "512ns"},
+		{"millisecond", "", 90000, 90000, "1.5min"},
+		{"second", "", 0.002, 0.0025, "2.5ms"},
+		{"byte", "", 3 << 30, 3.5 * (1 << 30), "3.5GiB"},
+		{"kibibyte", "", 512, 512, "512KiB"},
+		{"bit", "second", 8e6, 8e6, "976.6KiB/s"},
+		{"request", "second", 12, 12.25, "12.25 request/s"},
+		{"", "hour", 3, 3, "3/h"},
+		{"connection", "shard", 3, 3, "3 connection/shard"},
+		{"byte", "", 0, 0, "0B"},
+	}
+	for _, tt := range tests {
+		f := seriesFormat(tt.unit, tt.perUnit, tt.magnitude)
+		if got := f.format(tt.value); got != tt.want {
+			t.Errorf("%s/%s at magnitude %g: format(%g) = %q, want %q", tt.unit, tt.perUnit, tt.magnitude, tt.value, got, tt.want)
+		}
+	}
+}